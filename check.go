@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// headerPrefix starts the header of every generated file, and is followed by
+// the command line that generated it.
+const headerPrefix = "// This file was auto generated using the following command:\n//    "
+
+// commandHeader returns the header of a generated file that records the
+// command line args. Arguments that the shell would split or interpret, and
+// always the final argument, are written as Go string literals, so that
+// splitCommand can recover them.
+func commandHeader(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if i == len(args)-1 || a == "" || strings.ContainsAny(a, " \t\"'\\$`*?[]{}()<>|&;#~!") || strconv.Quote(a) != `"`+a+`"` {
+			quoted[i] = strconv.Quote(a)
+		} else {
+			quoted[i] = a
+		}
+	}
+	return headerPrefix + strings.Join(quoted, " ") + "\n\n"
+}
+
+// splitCommand returns the arguments of the command line recorded by
+// commandHeader.
+func splitCommand(line string) ([]string, error) {
+	var args []string
+	for line = strings.TrimLeft(line, " "); line != ""; line = strings.TrimLeft(line, " ") {
+		if line[0] != '"' {
+			i := strings.IndexByte(line, ' ')
+			if i < 0 {
+				i = len(line)
+			}
+			args = append(args, line[:i])
+			line = line[i:]
+			continue
+		}
+		if quoted, err := strconv.QuotedPrefix(line); err == nil && (len(quoted) == len(line) || line[len(quoted)] == ' ') {
+			a, err := strconv.Unquote(quoted)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, a)
+			line = line[len(quoted):]
+			continue
+		}
+		// Older versions wrote the final argument between double quotes,
+		// without escaping it.
+		if len(line) < 2 || line[len(line)-1] != '"' {
+			return nil, fmt.Errorf("cannot find end of quoted argument: %s", line)
+		}
+		args = append(args, line[1:len(line)-1])
+		break
+	}
+	if len(args) == 0 {
+		return nil, errors.New("cannot find command")
+	}
+	return args, nil
+}
+
+// check regenerates the file named name with the command line recorded in
+// its header, and returns an error after writing a diff to w when the file is
+// not up to date. A file whose name ends in _test.go is
+// compared with the test file that the command generates.
+func check(name string, w io.Writer) error {
+	blob, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if !bytes.HasPrefix(blob, []byte(headerPrefix)) {
+		return fmt.Errorf("cannot find generated file header in %s", name)
+	}
+	end := bytes.Index(blob, []byte("\n\n"))
+	if end < 0 {
+		return fmt.Errorf("cannot find end of generated file header in %s", name)
+	}
+	header := string(blob[:end+2])
+
+	args, err := splitCommand(strings.TrimPrefix(header[:end], headerPrefix))
+	if err != nil {
+		return fmt.Errorf("cannot parse command in %s: %w", name, err)
+	}
+	o, err := parseOptions(args[0], args[1:], flag.ContinueOnError)
+	if err != nil {
+		return fmt.Errorf("cannot parse command in %s: %w", name, err)
+	}
+	if o.check != "" || (o.manifest == "" && len(o.args) != 1) || (o.manifest != "" && len(o.args) != 0) {
+		return fmt.Errorf("cannot regenerate %s from command: %s", name, header[len(headerPrefix):end])
+	}
+
+	cg, err := o.generate(header, filepath.Dir(name))
+	if err != nil {
+		return fmt.Errorf("cannot regenerate %s: %w", name, err)
+	}
+
+	var want bytes.Buffer
+	if strings.HasSuffix(name, "_test.go") {
+		if !o.test && !o.bench {
+			return fmt.Errorf("cannot regenerate %s from command without -test or -bench", name)
+		}
+		_, err = cg.WriteTestTo(&want)
+	} else {
+		_, err = cg.WriteTo(&want)
+	}
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(blob, want.Bytes()) {
+		return nil
+	}
+	if _, err = w.Write(diff(name, blob, want.Bytes())); err != nil {
+		return err
+	}
+	return fmt.Errorf("%s is not up to date", name)
+}
+
+// maxTable bounds the number of cells of the table of longest common
+// subsequences that diff builds, so that a regenerated file that differs
+// everywhere does not require quadratic memory.
+const maxTable = 1 << 22
+
+// diff returns the differences between the lines of got and want, in the
+// unified format of diff -u, with three lines of context.
+func diff(name string, got, want []byte) []byte {
+	a, b := splitLines(got), splitLines(want)
+
+	// Each edit is a line prefixed with ' ', '-', or '+', along with its
+	// line numbers in got and want.
+	type edit struct {
+		op   byte
+		line string
+		i, j int
+	}
+	var edits []edit
+
+	// Only the lines between the common prefix and the common suffix need
+	// to be compared.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		edits = append(edits, edit{' ', a[prefix], prefix, prefix})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// lcs[i][j] is the length of the longest common subsequence of ma[i:]
+	// and mb[j:]. When the table would be too large, it is left empty, and
+	// every line of ma is replaced by every line of mb.
+	var lcs [][]int
+	if (len(ma)+1)*(len(mb)+1) <= maxTable {
+		lcs = make([][]int, len(ma)+1)
+		for i := range lcs {
+			lcs[i] = make([]int, len(mb)+1)
+		}
+		for i := len(ma) - 1; i >= 0; i-- {
+			for j := len(mb) - 1; j >= 0; j-- {
+				if ma[i] == mb[j] {
+					lcs[i][j] = lcs[i+1][j+1] + 1
+				} else if lcs[i+1][j] >= lcs[i][j+1] {
+					lcs[i][j] = lcs[i+1][j]
+				} else {
+					lcs[i][j] = lcs[i][j+1]
+				}
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(ma) || j < len(mb) {
+		switch {
+		case lcs != nil && i < len(ma) && j < len(mb) && ma[i] == mb[j]:
+			edits = append(edits, edit{' ', ma[i], prefix + i, prefix + j})
+			i++
+			j++
+		case j == len(mb) || (i < len(ma) && (lcs == nil || lcs[i+1][j] >= lcs[i][j+1])):
+			edits = append(edits, edit{'-', ma[i], prefix + i, prefix + j})
+			i++
+		default:
+			edits = append(edits, edit{'+', mb[j], prefix + i, prefix + j})
+			j++
+		}
+	}
+	for k := len(a) - suffix; k < len(a); k++ {
+		edits = append(edits, edit{' ', a[k], k, k - len(a) + len(b)})
+	}
+
+	const context = 3
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s\n+++ %s (regenerated)\n", name, name)
+	for k := 0; k < len(edits); {
+		if edits[k].op == ' ' {
+			k++
+			continue
+		}
+		// A hunk extends until the changes are separated by more than
+		// twice the context.
+		start := k - context
+		if start < 0 {
+			start = 0
+		}
+		end := k
+		for end < len(edits) {
+			if edits[end].op != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].op == ' ' {
+				run++
+			}
+			if run == len(edits) || run-end > 2*context {
+				end += context
+				if end > run {
+					end = run
+				}
+				break
+			}
+			end = run
+		}
+
+		var oldLines, newLines int
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				oldLines++
+			}
+			if e.op != '-' {
+				newLines++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", edits[start].i+1, oldLines, edits[start].j+1, newLines)
+		for _, e := range edits[start:end] {
+			buf.WriteByte(e.op)
+			buf.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		k = end
+	}
+	return buf.Bytes()
+}
+
+// splitLines returns the lines of blob, each with its newline.
+func splitLines(blob []byte) []string {
+	lines := strings.SplitAfter(string(blob), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
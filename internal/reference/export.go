@@ -0,0 +1,54 @@
+package reference
+
+import (
+	_ "embed" // for the source embedded in fuzz tests
+	"time"
+)
+
+// Source is the source code of the interpreter, which sftgen embeds in the
+// fuzz tests it emits.
+//
+//go:embed strftime.go
+var Source string
+
+// Interpret appends spec to buf, with the text that verb returns in place of
+// each formatting verb and its flags. The final argument of verb is the byte
+// index of the verb within spec, and verb is 0 when spec ends with a percent
+// sign.
+func Interpret(buf []byte, spec string, verb func(buf []byte, verb, pad byte, upcase, swapcase bool, width, colons, index int) []byte) []byte {
+	return referenceInterpret(buf, spec, verb)
+}
+
+// Fields holds the calendar, clock, and zone fields of a time, so that they
+// are computed once however many verbs write them.
+type Fields = referenceFields
+
+// FieldsOf returns the fields of t.
+func FieldsOf(t time.Time) Fields {
+	return referenceFieldsOf(t)
+}
+
+// Composite returns the spec that the composite verb writes, with the '^'
+// flag added to its names when upcase is true, or "" when verb is not a
+// composite verb.
+func Composite(verb byte, upcase bool) string {
+	return referenceComposite(verb, upcase)
+}
+
+// Pad pads the text appended to buf after start on the left to width with
+// spaces, or with zeros when pad is '0', and not at all when pad is '-'.
+func Pad(buf []byte, start int, pad byte, width int) []byte {
+	return referencePad(buf, start, pad, width)
+}
+
+// AppendVerb appends the text written by a single formatting verb, with its
+// flags, for the time whose fields are f to buf. It panics when verb is not a
+// formatting verb.
+func AppendVerb(buf []byte, verb, pad byte, upcase, swapcase bool, width, colons int, f *Fields) []byte {
+	return referenceVerb(buf, verb, pad, upcase, swapcase, width, colons, f, referenceEnglish)
+}
+
+// Format appends t formatted according to spec to buf.
+func Format(buf []byte, spec string, t time.Time) []byte {
+	return referenceFormat(buf, spec, t)
+}
@@ -0,0 +1,627 @@
+// Package reference is a straightforward interpreter of the strftime specs
+// that sftgen accepts. It favors being obviously correct over being fast. It
+// is the single implementation of the semantics of the verbs outside of the
+// generator: the strftime package formats with it, and the fuzz tests emitted
+// by sftgen compare generated functions against it.
+//
+// The source of this file is embedded in those fuzz tests, after every
+// top-level identifier is renamed so that several copies can exist in the
+// same package. Therefore every top-level identifier in this file must start
+// with "reference", and this file must only import standard library
+// packages.
+package reference
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// referenceLocale holds the names written for weekdays, months, and the
+// AM/PM indicator.
+type referenceLocale struct {
+	weekdaysLong, weekdaysShort [7]string
+	monthsLong, monthsShort     [12]string
+	am, pm                      string
+}
+
+// referenceEnglish is the locale used by referenceFormat.
+var referenceEnglish = &referenceLocale{
+	weekdaysLong:  [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	weekdaysShort: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	monthsLong:    [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	monthsShort:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	am:            "AM",
+	pm:            "PM",
+}
+
+// referenceFormat appends t formatted according to spec to buf.
+func referenceFormat(buf []byte, spec string, t time.Time) []byte {
+	return referenceFormatLocale(buf, spec, t, referenceEnglish)
+}
+
+// referenceFormatLocale appends t formatted according to spec to buf, using
+// the names of the locale loc.
+func referenceFormatLocale(buf []byte, spec string, t time.Time, loc *referenceLocale) []byte {
+	f := referenceFieldsOf(t)
+	return referenceFormatFields(buf, spec, &f, loc)
+}
+
+// referenceFormatFields appends the time whose fields are f formatted
+// according to spec to buf, using the names of the locale loc.
+func referenceFormatFields(buf []byte, spec string, f *referenceFields, loc *referenceLocale) []byte {
+	return referenceInterpret(buf, spec, func(buf []byte, verb, pad byte, upcase, swapcase bool, width, colons, _ int) []byte {
+		return referenceVerb(buf, verb, pad, upcase, swapcase, width, colons, f, loc)
+	})
+}
+
+// referenceFields holds the calendar, clock, and zone fields of a time, so
+// that they are computed once however many verbs write them.
+type referenceFields struct {
+	year, month, day                 int
+	hour, minute, second, nanosecond int
+	isoYear, isoWeek                 int
+	weekday, yearday                 int
+	zoneName                         string
+	zoneOffset                       int
+	unix                             int64
+}
+
+// referenceFieldsOf returns the fields of t.
+func referenceFieldsOf(t time.Time) referenceFields {
+	var f referenceFields
+	var month time.Month
+	f.year, month, f.day = t.Date()
+	f.month = int(month)
+	f.hour, f.minute, f.second = t.Clock()
+	f.nanosecond = t.Nanosecond()
+	f.isoYear, f.isoWeek = t.ISOWeek()
+	f.zoneName, f.zoneOffset = t.Zone()
+	f.weekday = int(t.Weekday())
+	f.yearday = t.YearDay()
+	f.unix = t.Unix()
+	return f
+}
+
+// referenceComposite returns the spec that the composite verb writes, with
+// the '^' flag added to its names when upcase is true, or "" when verb is
+// not a composite verb.
+func referenceComposite(verb byte, upcase bool) string {
+	var spec string
+	switch verb {
+	case 'c':
+		spec = "%a %b %e %H:%M:%S %Y"
+	case 'D', 'x':
+		spec = "%m/%d/%y"
+	case 'F':
+		spec = "%Y-%m-%d"
+	case 'r':
+		spec = "%I:%M:%S %p"
+	case 'R':
+		spec = "%H:%M"
+	case 'T', 'X':
+		spec = "%H:%M:%S"
+	case '+':
+		spec = "%a %b %e %H:%M:%S %p %Z %Y"
+	}
+	if upcase {
+		spec = strings.ReplaceAll(spec, "%a", "%^a")
+		spec = strings.ReplaceAll(spec, "%b", "%^b")
+		spec = strings.ReplaceAll(spec, "%Z", "%^Z")
+	}
+	return spec
+}
+
+// referenceInterpret appends spec to buf, with the text that verb returns in
+// place of each formatting verb and its flags. The final argument of verb is
+// the byte index of the verb within spec.
+func referenceInterpret(buf []byte, spec string, verb func(buf []byte, verb, pad byte, upcase, swapcase bool, width, colons, index int) []byte) []byte {
+	for i := 0; i < len(spec); i++ {
+		if spec[i] != '%' {
+			buf = append(buf, spec[i])
+			continue
+		}
+
+		var pad byte
+		var upcase, swapcase bool
+		var width, widthDigits int
+
+		// Flags.
+	flags:
+		for i++; i < len(spec); i++ {
+			switch spec[i] {
+			case '-', '_', '0':
+				pad = spec[i]
+			case '^':
+				upcase = true
+			case '#':
+				swapcase = true
+			default:
+				break flags
+			}
+		}
+
+		// Field width.
+		for ; i < len(spec) && '0' <= spec[i] && spec[i] <= '9'; i++ {
+			width = width*10 + int(spec[i]-'0')
+			widthDigits++
+		}
+
+		// Colons select the form of the zone offset written by %z. A single
+		// digit followed by colons is an extra verb rather than a width,
+		// unless the colons are followed by z.
+		var colons int
+		for ; (widthDigits != 1 || strings.HasPrefix(strings.TrimLeft(spec[i:], ":"), "z")) && i < len(spec) && spec[i] == ':'; i++ {
+			colons++
+		}
+
+		var c byte
+		if i < len(spec) {
+			c = spec[i]
+		}
+		if widthDigits == 1 && !referenceIsVerb(c) {
+			// The extra verbs, %1 through %4, look like a single digit
+			// field width that is not followed by a verb.
+			c = byte('0' + width)
+			width = 0
+			i--
+		}
+
+		buf = verb(buf, c, pad, upcase, swapcase, width, colons, i)
+	}
+	return buf
+}
+
+// referenceIsVerb returns true when c may be a formatting verb that follows
+// a field width. The percent sign is excluded so that an extra verb may be
+// immediately followed by another verb, as in "%3%1".
+func referenceIsVerb(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || c == '+'
+}
+
+// referenceVerb appends the text for a single formatting verb to buf, for
+// the time whose fields are f. A composite verb is padded to the field width
+// as a whole.
+func referenceVerb(buf []byte, verb, pad byte, upcase, swapcase bool, width, colons int, f *referenceFields, loc *referenceLocale) []byte {
+	if spec := referenceComposite(verb, upcase); spec != "" {
+		start := len(buf)
+		return referencePad(referenceFormatFields(buf, spec, f, loc), start, pad, width)
+	}
+
+	// Like time.Format, the last two digits of a negative year are the same
+	// as those of the positive year.
+	yearInCentury, isoYearInCentury := f.year%100, f.isoYear%100
+	if yearInCentury < 0 {
+		yearInCentury = -yearInCentury
+	}
+	if isoYearInCentury < 0 {
+		isoYearInCentury = -isoYearInCentury
+	}
+
+	hour12 := f.hour % 12
+	if hour12 == 0 {
+		hour12 = 12
+	}
+
+	// Names are mapped to upper case for the '^' and '#' flags.
+	var names func(rune) rune
+	if upcase || swapcase {
+		names = unicode.ToUpper
+	}
+	name := func(s string, mapping func(rune) rune) []byte {
+		start := len(buf)
+		return referencePad(referenceMap(buf, s, mapping), start, pad, width)
+	}
+	number := func(value, digits int, defaultPad byte) []byte {
+		return referenceNumber(buf, value, digits, defaultPad, pad, width)
+	}
+
+	switch verb {
+	case 'a':
+		return name(loc.weekdaysShort[f.weekday], names)
+	case 'A':
+		return name(loc.weekdaysLong[f.weekday], names)
+	case 'b', 'h':
+		return name(loc.monthsShort[f.month-1], names)
+	case 'B':
+		return name(loc.monthsLong[f.month-1], names)
+	case 'C':
+		// The century has the sign of the year, which always counts toward
+		// its width.
+		return referenceSigned(buf, f.year < 0, f.year/100, 2, pad, width, true)
+	case 'd':
+		return number(f.day, 2, '0')
+	case 'e':
+		return number(f.day, 2, '_')
+	case 'g':
+		return number(isoYearInCentury, 2, '0')
+	case 'G':
+		return referenceYear(buf, f.isoYear, pad, width)
+	case 'H':
+		return number(f.hour, 2, '0')
+	case 'I':
+		return number(hour12, 2, '0')
+	case 'j':
+		return number(f.yearday, 3, '0')
+	case 'k':
+		return number(f.hour, 2, '_')
+	case 'l':
+		return number(hour12, 2, '_')
+	case 'm':
+		return number(f.month, 2, '0')
+	case 'M':
+		return number(f.minute, 2, '0')
+	case 'n':
+		return referenceString(buf, "\n", pad, width)
+	case 'N':
+		return referenceFraction(buf, f.nanosecond, 9, pad, width)
+	case 'p':
+		ampm := loc.pm
+		if f.hour < 12 {
+			ampm = loc.am
+		}
+		if swapcase {
+			return name(ampm, unicode.ToLower)
+		}
+		if upcase {
+			return name(ampm, unicode.ToUpper)
+		}
+		return name(ampm, nil)
+	case 'P':
+		if f.hour < 12 {
+			return name(loc.am, unicode.ToLower)
+		}
+		return name(loc.pm, unicode.ToLower)
+	case 's':
+		epoch := int(f.unix)
+		return referenceSigned(buf, epoch < 0, epoch, 1, pad, width, true)
+	case 'S':
+		return number(f.second, 2, '0')
+	case 't':
+		return referenceString(buf, "\t", pad, width)
+	case 'u':
+		if f.weekday == 0 {
+			return number(7, 1, '0')
+		}
+		return number(f.weekday, 1, '0')
+	case 'U':
+		return number((f.yearday+6-f.weekday)/7, 2, '0')
+	case 'V':
+		return number(f.isoWeek, 2, '0')
+	case 'w':
+		return number(f.weekday, 1, '0')
+	case 'W':
+		return number((f.yearday+6-(f.weekday+6)%7)/7, 2, '0')
+	case 'y':
+		return number(yearInCentury, 2, '0')
+	case 'Y':
+		return referenceYear(buf, f.year, pad, width)
+	case 'z':
+		return referenceZone(buf, f.zoneOffset, colons, pad, width)
+	case 'Z':
+		switch {
+		case swapcase:
+			return name(f.zoneName, unicode.ToLower)
+		case upcase:
+			return name(f.zoneName, unicode.ToUpper)
+		}
+		return name(f.zoneName, nil)
+	case '%':
+		return referenceString(buf, "%", pad, width)
+	case '1':
+		if f.zoneOffset == 0 {
+			return append(buf, 'Z')
+		}
+		return referenceOffset(buf, f.zoneOffset, 1)
+	case '2':
+		return strconv.AppendInt(buf, int64(hour12), 10)
+	case '3':
+		return referenceNumber(buf, f.nanosecond/1000000, 3, '0', '0', 0)
+	case '4':
+		return referenceNumber(buf, f.nanosecond/1000, 6, '0', '0', 0)
+	}
+
+	panic("cannot recognize format verb " + strconv.Quote(string(verb)))
+}
+
+// referenceFormatDuration appends d formatted according to spec to buf. The
+// largest of the days, hours, minutes, and seconds in spec is written in
+// full, and each smaller unit is what remains after the larger ones. A
+// negative duration is written with a minus sign before its first number,
+// unless spec writes its sign with %+.
+func referenceFormatDuration(buf []byte, spec string, d time.Duration) []byte {
+	// The magnitude of the minimum duration does not fit in a time.Duration.
+	magnitude := uint64(d)
+	if d < 0 {
+		magnitude = -magnitude
+	}
+	seconds, nanos := int(magnitude/1000000000), int(magnitude%1000000000)
+
+	units := map[byte]struct{ per, limit int }{
+		'd': {86400, 0},
+		'H': {3600, 24},
+		'M': {60, 60},
+		'S': {1, 60},
+	}
+
+	// Find the largest unit, and whether the sign is written explicitly, by
+	// formatting the spec once without output.
+	var largest byte
+	var signed bool
+	referenceInterpret(nil, spec, func(buf []byte, verb, _ byte, _, _ bool, _, _, _ int) []byte {
+		if u, ok := units[verb]; ok && (largest == 0 || u.per > units[largest].per) {
+			largest = verb
+		}
+		signed = signed || verb == '+'
+		return buf
+	})
+
+	return referenceInterpret(buf, spec, func(buf []byte, verb, pad byte, _, _ bool, width, _, _ int) []byte {
+		if u, ok := units[verb]; ok || strings.IndexByte("N34", verb) >= 0 {
+			if !signed {
+				if d < 0 {
+					buf = append(buf, '-')
+				}
+				signed = true
+			}
+			if ok {
+				value := seconds / u.per
+				if verb != largest {
+					value %= u.limit
+				}
+				digits := 2
+				if verb == 'd' {
+					digits = 1
+				}
+				return referenceNumber(buf, value, digits, '0', pad, width)
+			}
+		}
+
+		switch verb {
+		case 'N':
+			return referenceFraction(buf, nanos, 9, pad, width)
+		case '3':
+			return referenceFraction(buf, nanos, 3, 0, 0)
+		case '4':
+			return referenceFraction(buf, nanos, 6, 0, 0)
+		case 'n':
+			return referenceString(buf, "\n", pad, width)
+		case 't':
+			return referenceString(buf, "\t", pad, width)
+		case '%':
+			return referenceString(buf, "%", pad, width)
+		case '+':
+			if d < 0 {
+				return referenceString(buf, "-", pad, width)
+			}
+			return referenceString(buf, "+", pad, width)
+		}
+
+		panic("cannot recognize format verb " + strconv.Quote(string(verb)) + " for durations")
+	})
+}
+
+// referenceNumber appends value, which is not negative, to buf, padded to the
+// larger of digits and width, using the padding flag pad, or defaultPad when
+// pad is 0.
+func referenceNumber(buf []byte, value, digits int, defaultPad, pad byte, width int) []byte {
+	if pad == 0 {
+		pad = defaultPad
+	}
+	if width < digits {
+		width = digits
+	}
+	start := len(buf)
+	return referencePad(strconv.AppendInt(buf, int64(value), 10), start, pad, width)
+}
+
+// referenceFraction appends the fractional second nanos to buf, truncated to
+// width digits, or to digits when width is 0. Like GNU, the '-' flag removes
+// the trailing zeros of the digits and the '_' flag replaces them with
+// spaces, always leaving the first digit, and a width beyond the 9 digits of
+// nanos pads them on the right with zeros, with spaces for the '_' flag, and
+// not at all for the '-' flag.
+func referenceFraction(buf []byte, nanos, digits int, pad byte, width int) []byte {
+	if width > 0 {
+		digits = width
+	}
+	written := digits
+	if written > 9 {
+		written = 9
+	}
+	for i := written; i < 9; i++ {
+		nanos /= 10
+	}
+	start := len(buf)
+	buf = referenceNumber(buf, nanos, written, '0', '0', 0)
+	fill := byte('0')
+	if pad == '-' || pad == '_' {
+		end := len(buf)
+		for end > start+1 && buf[end-1] == '0' {
+			end--
+		}
+		if pad == '-' {
+			return buf[:end]
+		}
+		for i := end; i < len(buf); i++ {
+			buf[i] = ' '
+		}
+		fill = ' '
+	}
+	for i := written; i < digits; i++ {
+		buf = append(buf, fill)
+	}
+	return buf
+}
+
+// referenceSigned appends the magnitude of value to buf, padded to the larger
+// of digits and width, and preceded by a minus sign when negative is true.
+// Like GNU, padding spaces are written before the sign and padding zeros
+// after it. The sign counts toward the width when signWidth is true, and
+// otherwise, like time.Format writes years, it is followed by the padded
+// digits.
+func referenceSigned(buf []byte, negative bool, value, digits int, pad byte, width int, signWidth bool) []byte {
+	if value < 0 {
+		value = -value
+	}
+	if width < digits {
+		width = digits
+	}
+	if negative && !signWidth {
+		width++
+	}
+	start := len(buf)
+	if negative {
+		buf = append(buf, '-')
+	}
+	switch pad {
+	case '-', '_':
+		return referencePad(strconv.AppendInt(buf, int64(value), 10), start, pad, width)
+	}
+	if negative {
+		start++
+		width--
+	}
+	return referencePad(strconv.AppendInt(buf, int64(value), 10), start, '0', width)
+}
+
+// referenceYear appends year to buf like time.Format writes it, or when width
+// is not 0, like GNU, padded to width with the sign counting toward it, even
+// when width is narrower than the 4 digits of the default.
+func referenceYear(buf []byte, year int, pad byte, width int) []byte {
+	if width == 0 {
+		return referenceSigned(buf, year < 0, year, 4, pad, 0, false)
+	}
+	return referenceSigned(buf, year < 0, year, 1, pad, width, true)
+}
+
+// referenceString appends s to buf, padded on the left to width with spaces,
+// or with zeros when pad is '0'.
+func referenceString(buf []byte, s string, pad byte, width int) []byte {
+	start := len(buf)
+	return referencePad(append(buf, s...), start, pad, width)
+}
+
+// referencePad pads the text appended to buf after start on the left to
+// width with spaces, or with zeros when pad is '0', and not at all when pad
+// is '-'.
+func referencePad(buf []byte, start int, pad byte, width int) []byte {
+	n := len(buf) - start
+	if pad == '-' || n >= width {
+		return buf
+	}
+	fill := byte(' ')
+	if pad == '0' {
+		fill = '0'
+	}
+	for i := n; i < width; i++ {
+		buf = append(buf, fill)
+	}
+	copy(buf[start+width-n:], buf[start:start+n])
+	for i := start; i < start+width-n; i++ {
+		buf[i] = fill
+	}
+	return buf
+}
+
+// referenceMap appends s to buf, with every rune changed by mapping, or
+// unchanged when mapping is nil.
+func referenceMap(buf []byte, s string, mapping func(rune) rune) []byte {
+	if mapping == nil {
+		return append(buf, s...)
+	}
+	var b [utf8.UTFMax]byte
+	for _, r := range s {
+		buf = append(buf, b[:utf8.EncodeRune(b[:], mapping(r))]...)
+	}
+	return buf
+}
+
+// referenceTwoDigits appends value, which is from 0 through 99, to buf as two
+// digits.
+func referenceTwoDigits(buf []byte, value int) []byte {
+	return append(buf, byte('0'+value/10), byte('0'+value%10))
+}
+
+// referenceZone appends the zone offset to buf, in the form that GNU date
+// writes for %z when colons is 0, %:z when it is 1, %::z when it is 2, and
+// %:::z when it is 3. Like GNU, the offset is a signed number whose hours are
+// padded to the width of the form, or to width when it is larger: padding
+// zeros are written after the sign, padding spaces before it, and the '-'
+// flag writes the hours without padding.
+func referenceZone(buf []byte, offset, colons int, pad byte, width int) []byte {
+	sign := byte('+')
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	if colons == 3 {
+		switch {
+		case offset%60 != 0:
+			colons = 2
+		case offset%3600 != 0:
+			colons = 1
+		}
+	}
+	hours, minutes, seconds := offset/3600, offset%3600/60, offset%60
+
+	start := len(buf)
+	buf = append(buf, sign)
+	digitsStart := len(buf)
+	var digits int
+	switch colons {
+	case 0:
+		buf, digits = strconv.AppendInt(buf, int64(hours*100+minutes), 10), 5
+	case 1:
+		buf, digits = referenceTwoDigits(append(strconv.AppendInt(buf, int64(hours), 10), ':'), minutes), 6
+	case 2:
+		buf = referenceTwoDigits(append(strconv.AppendInt(buf, int64(hours), 10), ':'), minutes)
+		buf, digits = referenceTwoDigits(append(buf, ':'), seconds), 9
+	default:
+		buf, digits = strconv.AppendInt(buf, int64(hours), 10), 3
+	}
+	if width == 0 {
+		width = digits
+	}
+
+	// The sign counts toward the width.
+	switch pad {
+	case '-':
+		return buf
+	case '_':
+		return referencePad(buf, start, pad, width)
+	}
+	return referencePad(buf, digitsStart, '0', width-1)
+}
+
+// referenceOffset appends the zone offset to buf as a sign followed by hours
+// and minutes, in the form that GNU date writes for %z when colons is 0, %:z
+// when it is 1, %::z when it is 2, and %:::z when it is 3.
+func referenceOffset(buf []byte, offset, colons int) []byte {
+	sign := byte('+')
+	if offset < 0 {
+		sign = '-'
+		offset = -offset
+	}
+	if colons == 3 {
+		switch {
+		case offset%60 != 0:
+			colons = 2
+		case offset%3600 != 0:
+			colons = 1
+		default:
+			return referenceTwoDigits(append(buf, sign), offset/3600)
+		}
+	}
+	buf = referenceTwoDigits(append(buf, sign), offset/3600)
+	if colons > 0 {
+		buf = append(buf, ':')
+	}
+	buf = referenceTwoDigits(buf, offset%3600/60)
+	if colons == 2 {
+		buf = referenceTwoDigits(append(buf, ':'), offset%60)
+	}
+	return buf
+}
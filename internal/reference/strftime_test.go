@@ -0,0 +1,199 @@
+package reference
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestReferenceFormat(t *testing.T) {
+	first := time.Unix(1136171045, 123456789).UTC()
+	second := time.Unix(946638000, 1000).UTC()
+
+	// Expected values were produced by GNU date.
+	cases := []struct {
+		spec string
+		when time.Time
+		want string
+	}{
+		{
+			spec: "%a %A %b %B %C %d %D %e %F %g %G %h %H %I %j %k %l %m %M %n %N %p %P %r %R %s %S %t %T %u %U %V %w %W %x %X %y %Y %z %Z %%",
+			when: first,
+			want: "Mon Monday Jan January 20 02 01/02/06  2 2006-01-02 06 2006 Jan 03 03 002  3  3 01 04 \n 123456789 AM am 03:04:05 AM 03:04 1136171045 05 \t 03:04:05 1 01 01 1 01 01/02/06 03:04:05 06 2006 +0000 UTC %",
+		},
+		{
+			spec: "%a %A %b %B %C %d %D %e %F %g %G %h %H %I %j %k %l %m %M %n %N %p %P %r %R %s %S %t %T %u %U %V %w %W %x %X %y %Y %z %Z %%",
+			when: second,
+			want: "Fri Friday Dec December 19 31 12/31/99 31 1999-12-31 99 1999 Dec 11 11 365 11 11 12 00 \n 000001000 AM am 11:00:00 AM 11:00 946638000 00 \t 11:00:00 5 52 52 5 52 12/31/99 11:00:00 99 1999 +0000 UTC %",
+		},
+		{
+			spec: "%-d %_H %010d %^a %#b %#p %#Z %3N %6N %10a %5j %_5j %-j",
+			when: first,
+			want: "2  3 0000000002 MON JAN am utc 123 123456        Mon 00002     2 2",
+		},
+		{
+			spec: "%-d %_H %010d %^a %#b %#p %#Z %3N %6N %10a %5j %_5j %-j",
+			when: second,
+			want: "31 11 0000000031 FRI DEC am utc 000 000001        Fri 00365   365 365",
+		},
+		{
+			spec: "%Y-%m-%dT%T.%3%1 %2",
+			when: first.In(time.FixedZone("XYZ", -7*3600)),
+			want: "2006-01-01T20:04:05.123-07:00 8",
+		},
+		{
+			spec: "%z %:z %::z %:::z",
+			when: time.Date(1800, time.January, 1, 0, 0, 0, 0, time.FixedZone("LMT", -(4*3600+56*60+2))),
+			want: "-0456 -04:56 -04:56:02 -04:56:02",
+		},
+		{
+			spec: "%z %:z %::z %:::z",
+			when: time.Unix(1152000000, 0).In(time.FixedZone("+1030", 10*3600+30*60)),
+			want: "+1030 +10:30 +10:30:00 +10:30",
+		},
+		{
+			spec: "%z %:z %::z %:::z",
+			when: first,
+			want: "+0000 +00:00 +00:00:00 +00",
+		},
+		{
+			spec: "%-z|%_z|%-:z|%_::z|%-:::z|%_:::z|%10z|%_10z",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 0, time.FixedZone("", -(5*3600+30*60))),
+			want: "-530| -530|-5:30| -5:30:00|-5:30| -5:30|-000000530|      -530",
+		},
+		{
+			spec: "%-z|%_z|%-:::z|%_:::z|%10:::z",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 0, time.FixedZone("", 10*3600)),
+			want: "+1000|+1000|+10|+10|+000000010",
+		},
+		{
+			spec: "%8:z|%_5:z|%9::z|%_8:::z|%8:::z|%1:z",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 0, time.FixedZone("", -(3*3600+30*60))),
+			want: "-0003:30|-3:30|-03:30:00|   -3:30|-0003:30|-3:30",
+		},
+		{
+			spec: "%10N|%_12N|%-12N|%012N",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 11, time.UTC),
+			want: "0000000110|000000011   |000000011|000000011000",
+		},
+		{
+			spec: "%-3N|%-12N|%_3N|%_N|%_12N|%-N",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 50000000, time.UTC),
+			want: "05|05|05 |05       |05          |05",
+		},
+		{
+			spec: "%1 %2 %4",
+			when: time.Date(2020, time.March, 4, 0, 5, 6, 7000, time.UTC),
+			want: "Z 12 000007",
+		},
+	}
+
+	for _, c := range cases {
+		if got, want := string(referenceFormat(nil, c.spec, c.when)), c.want; got != want {
+			t.Errorf("%q: GOT: %q; WANT: %q", c.spec, got, want)
+		}
+	}
+}
+
+func TestReferenceFormatNegative(t *testing.T) {
+	ides := time.Date(-44, time.March, 15, 12, 30, 0, 0, time.UTC)
+
+	// Like GNU, the minus sign counts toward the field width, with padding
+	// spaces before it and padding zeros after it.
+	cases := []struct {
+		spec string
+		when time.Time
+		want string
+	}{
+		{spec: "%s|%12s|%13s|%_13s|%-13s", when: ides, want: "-63549315000|-63549315000|-063549315000| -63549315000|-63549315000"},
+		{spec: "%12s|%013s|%_13s", when: time.Unix(-48746860373, 0).UTC(), want: "-48746860373|-048746860373| -48746860373"},
+		{spec: "%Y|%5Y|%6Y|%_6Y|%-6Y", when: ides, want: "-0044|-0044|-00044|   -44|-44"},
+		{spec: "%G|%5G|%6G|%_6G|%-6G", when: ides, want: "-0044|-0044|-00044|   -44|-44"},
+	}
+
+	for _, c := range cases {
+		if got, want := string(referenceFormat(nil, c.spec, c.when)), c.want; got != want {
+			t.Errorf("%q: GOT: %q; WANT: %q", c.spec, got, want)
+		}
+	}
+}
+
+func TestReferenceFormatYears(t *testing.T) {
+	// Like GNU, the sign of a negative year counts toward an explicit field
+	// width, with padding spaces before it and padding zeros after it.
+	// Without a field width, %Y and %G write years like time.Format.
+	const spec = "%Y|%_10Y|%010Y|%-Y|%_Y|%3Y|%C|%_C|%-C|%4C|%G|%6G|%_6G|%2Y|%_3G"
+	cases := []struct {
+		year int
+		want string
+	}{
+		{44, "0044|        44|0000000044|44|  44|044|00| 0|0|0000|0044|000044|    44|44| 44"},
+		{-1, "-0001|        -1|-000000001|-1|   -1|-01|-0|-0|-0|-000|-0001|-00001|    -1|-1| -1"},
+		{-150, "-0150|      -150|-000000150|-150| -150|-150|-1|-1|-1|-001|-0150|-00150|  -150|-150|-150"},
+		{-12345, "-12345|    -12345|-000012345|-12345|-12345|-12345|-123|-123|-123|-123|-12345|-12345|-12345|-12345|-12345"},
+	}
+
+	for _, c := range cases {
+		when := time.Date(c.year, time.June, 15, 0, 0, 0, 0, time.UTC)
+		if got, want := string(referenceFormat(nil, spec, when)), c.want; got != want {
+			t.Errorf("%d: GOT: %q; WANT: %q", c.year, got, want)
+		}
+	}
+}
+
+func TestReferenceFormatLocale(t *testing.T) {
+	es := &referenceLocale{
+		weekdaysLong:  [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		weekdaysShort: [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		monthsLong:    [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		monthsShort:   [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+		am:            "a. m.",
+		pm:            "p. m.",
+	}
+	when := time.Date(2024, time.March, 6, 15, 4, 5, 0, time.UTC)
+
+	spec := "%A %a %B %b %p %P %^p %#p %^a %r"
+	if got, want := string(referenceFormatLocale(nil, spec, when, es)), "miércoles mié marzo mar p. m. p. m. P. M. p. m. MIÉ 03:04:05 p. m."; got != want {
+		t.Errorf("%q: GOT: %q; WANT: %q", spec, got, want)
+	}
+}
+
+func TestReferenceFormatDuration(t *testing.T) {
+	cases := []struct {
+		spec string
+		when time.Duration
+		want string
+	}{
+		{
+			spec: "%H:%M:%S.%3",
+			when: 26*time.Hour + 3*time.Minute + 4*time.Second + 123456789,
+			want: "26:03:04.123",
+		},
+		{
+			spec: "%dd %H:%M:%S.%N",
+			when: -(26*time.Hour + 3*time.Minute + 4*time.Second + 123456789),
+			want: "-1d 02:03:04.123456789",
+		},
+		{
+			spec: "%+%M:%S %-H:%_M:%5S|%10d|%4|%-d|%5+",
+			when: 93784*time.Second + 5000,
+			want: "+03:04 2: 3:00004|0000000001|000005|1|    +",
+		},
+		{
+			spec: "%S.%N",
+			when: math.MinInt64,
+			want: "-9223372036.854775808",
+		},
+		{
+			spec: "%dd%%%n%H",
+			when: 0,
+			want: "0d%\n00",
+		},
+	}
+
+	for _, c := range cases {
+		if got, want := string(referenceFormatDuration(nil, c.spec, c.when)), c.want; got != want {
+			t.Errorf("%q: GOT: %q; WANT: %q", c.spec, got, want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -8,53 +9,87 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/karrick/sft/sftgen"
 )
 
+// options holds the command line flags and arguments.
+type options struct {
+	append, bench, debug, duration, extra, layout bool
+	emitMain, pairs                               bool
+	sync, test, truncate, writer                  bool
+	cache, check, funcName, locale, manifest, out string
+	packageName, parse, typeName, zone            string
+	args                                          []string
+}
+
+// parseOptions parses the command line flags and arguments in args, which
+// excludes the program name.
+func parseOptions(name string, args []string, handling flag.ErrorHandling) (*options, error) {
+	o := new(options)
+	fs := flag.NewFlagSet(name, handling)
+	fs.BoolVar(&o.append, "append", false, "use append")
+	fs.BoolVar(&o.bench, "bench", false, "also emit a _test.go file with benchmarks next to the output file")
+	fs.StringVar(&o.cache, "cache", "", "name of a caching formatter type to also emit, that reuses the text of the previous second")
+	fs.StringVar(&o.check, "check", "", "name of a generated file to verify is up to date with the command in its header")
+	fs.BoolVar(&o.debug, "debug", false, "annotate emitted code with the generator methods that emitted it")
+	fs.BoolVar(&o.duration, "duration", false, "format a time.Duration rather than a time.Time, with the duration verbs")
+	fs.BoolVar(&o.extra, "extra", false, "allow non-standard formatting verbs")
+	fs.StringVar(&o.funcName, "f", "appendTime", "name of append function")
+	fs.StringVar(&o.locale, "locale", "en", "names of weekdays, months, and AM/PM: de, en, es, fr, or the name of a JSON locale file")
+	fs.BoolVar(&o.layout, "layout", false, "interpret format spec as a Go reference layout, as used by time.Format")
+	fs.BoolVar(&o.emitMain, "m", false, "emit a main function")
+	fs.StringVar(&o.manifest, "manifest", "", "name of manifest file mapping function names to format specs")
+	fs.StringVar(&o.out, "o", "", "name of file to output")
+	fs.StringVar(&o.packageName, "p", "main", "name of package to use")
+	fs.BoolVar(&o.pairs, "pairs", false, "write numbers two digits at a time from a 200 byte table of digit pairs")
+	fs.StringVar(&o.parse, "parse", "", "name of parse function to also emit")
+	fs.BoolVar(&o.sync, "sync", false, "with -cache, make the caching formatter type safe for concurrent use")
+	fs.BoolVar(&o.test, "test", false, "also emit a _test.go file with a fuzz test next to the output file")
+	fs.StringVar(&o.typeName, "type", "", "name of a formatter type to also emit, with AppendFormat, Format, MaxLen, and Spec methods")
+	fs.BoolVar(&o.truncate, "truncate", false, "with -append, discard the existing contents of the byte slice")
+	fs.BoolVar(&o.writer, "writer", false, "write to a *bufio.Writer rather than a byte slice")
+	fs.StringVar(&o.zone, "zone", "abbrev", "what %Z writes: abbrev, offset (abbreviation, or offset when empty), or location")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	o.args = fs.Args()
+
+	// A duration function is not named after times, unless named explicitly.
+	if o.duration {
+		named := false
+		fs.Visit(func(f *flag.Flag) { named = named || f.Name == "f" })
+		if !named {
+			o.funcName = "formatDuration"
+		}
+	}
+	return o, nil
+}
+
 func main() {
-	optAppend := flag.Bool("append", false, "use append")
-	optDebug := flag.Bool("debug", false, "elide reformatting using gofmt")
-	optExtra := flag.Bool("extra", false, "allow non-standard formatting verbs")
-	optFuncname := flag.String("f", "appendTime", "name of append function")
-	optMain := flag.Bool("m", false, "emit a main function")
-	optOutput := flag.String("o", "", "name of file to output")
-	optPackage := flag.String("p", "main", "name of package to use")
-	flag.Parse()
-
-	if flag.NArg() != 1 {
-		fmt.Fprintf(os.Stderr, "USAGE: %s [-f FUNCNAME] [-o OUTPUT_FILE] [-p PACKAGE] FORMAT_SPEC\n", filepath.Base(os.Args[0]))
-		os.Exit(2)
-	}
-
-	extra := *optExtra
-	spec := flag.Arg(0)
-
-	if a, ok := formatMap[spec]; ok {
-		spec = a
-		extra = true
-	}
-
-	args := make([]string, len(os.Args))
-	for i, a := range os.Args {
-		if i < len(args)-1 {
-			args[i] = a
-		} else {
-			args[i] = "\"" + a + "\""
+	o, _ := parseOptions(os.Args[0], os.Args[1:], flag.ExitOnError)
+
+	if o.check != "" {
+		if len(o.args) != 0 {
+			usage()
+		}
+		if err := check(o.check, os.Stdout); err != nil {
+			bail(err)
 		}
+		return
 	}
-	header := fmt.Sprintf(`// This file was auto generated using the following command:
-//    %s
 
-`, strings.Join(args, " "))
+	if (o.manifest == "" && len(o.args) != 1) || (o.manifest != "" && len(o.args) != 0) {
+		usage()
+	}
+	if o.test && o.out == "" {
+		bail(errors.New("cannot emit test without output file"))
+	}
+	if o.bench && o.out == "" {
+		bail(errors.New("cannot emit benchmark without output file"))
+	}
 
-	cg, err := NewCodeGenerator(spec, &Config{
-		Package:    *optPackage,
-		FuncName:   *optFuncname,
-		Header:     header,
-		AllowExtra: extra,
-		UseAppend:  *optAppend,
-		EmitMain:   *optMain,
-		Reformat:   !*optDebug,
-	})
+	cg, err := o.generate(commandHeader(os.Args), "")
 	if err != nil {
 		bail(err)
 	}
@@ -62,8 +97,8 @@ func main() {
 	var iow io.Writer = os.Stdout
 	var fh *os.File
 
-	if *optOutput != "" {
-		fh, err = os.Create(*optOutput)
+	if o.out != "" {
+		fh, err = os.Create(o.out)
 		if err != nil {
 			bail(err)
 		}
@@ -79,6 +114,112 @@ func main() {
 			bail(err)
 		}
 	}
+
+	if o.test || o.bench {
+		fh, err = os.Create(strings.TrimSuffix(o.out, ".go") + "_test.go")
+		if err != nil {
+			bail(err)
+		}
+		if _, err = cg.WriteTestTo(fh); err != nil {
+			bail(err)
+		}
+		if err = fh.Close(); err != nil {
+			bail(err)
+		}
+	}
+}
+
+// generate returns the code generator for the options, whose output starts
+// with header. Relative names of manifest and locale files are resolved
+// against dir.
+func (o *options) generate(header, dir string) (*sftgen.CodeGenerator, error) {
+	zoneStyle, ok := zoneStyles[o.zone]
+	if !ok {
+		return nil, fmt.Errorf("cannot recognize zone style %q", o.zone)
+	}
+
+	name := o.locale
+	if filepath.Ext(name) != "" {
+		name = resolve(dir, name) // a locale file rather than a built-in locale
+	}
+	locale, err := loadLocale(name)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := o.layout
+	var spec string
+	var functions []sftgen.Function
+
+	if o.manifest != "" {
+		fh, err := os.Open(resolve(dir, o.manifest))
+		if err != nil {
+			return nil, err
+		}
+		functions, err = sftgen.ParseManifest(fh)
+		_ = fh.Close()
+		if err != nil {
+			return nil, err
+		}
+		if !o.layout {
+			for i, f := range functions {
+				if l, ok := lookupLayout(f.Spec); ok {
+					functions[i].Spec = l
+					functions[i].Layout = true
+				}
+			}
+		}
+	} else {
+		spec = o.args[0]
+		if l, ok := lookupLayout(spec); ok && !o.layout {
+			spec = l
+			layout = true
+		}
+	}
+
+	config := &sftgen.Config{
+		Package:    o.packageName,
+		FuncName:   o.funcName,
+		Header:     header,
+		AllowExtra: o.extra,
+		UseAppend:  o.append,
+		Truncate:   o.truncate,
+		UseWriter:  o.writer,
+		EmitMain:   o.emitMain,
+		Annotate:   o.debug,
+		Layout:     layout,
+		Duration:   o.duration,
+		DigitPairs: o.pairs,
+		EmitTest:   o.test,
+		EmitBench:  o.bench,
+		ZoneStyle:  zoneStyle,
+		Locale:     locale,
+		CacheType:  o.cache,
+		CacheSync:  o.sync,
+
+		ParseFuncName: o.parse,
+		FormatterType: o.typeName,
+	}
+
+	if functions != nil {
+		return sftgen.NewManifestCodeGenerator(functions, config)
+	}
+	return sftgen.NewCodeGenerator(spec, config)
+}
+
+// resolve returns name, joined to dir when it is a relative file name.
+func resolve(dir, name string) string {
+	if dir == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "USAGE: %s [-f FUNCNAME] [-o OUTPUT_FILE] [-p PACKAGE] [-layout] FORMAT_SPEC\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "       %s [-o OUTPUT_FILE] [-p PACKAGE] -manifest MANIFEST_FILE\n", filepath.Base(os.Args[0]))
+	fmt.Fprintf(os.Stderr, "       %s -check GENERATED_FILE\n", filepath.Base(os.Args[0]))
+	os.Exit(2)
 }
 
 func bail(err error) {
@@ -86,40 +227,58 @@ func bail(err error) {
 	os.Exit(1)
 }
 
-var formatMap map[string]string
-
-func init() {
-	formatMap = map[string]string{
-		time.ANSIC:       "%c",
-		time.UnixDate:    "%a %b %e %T %Z %Y",  // "Mon Jan _2 15:04:05 MST 2006",
-		time.RubyDate:    "%a %b %d %T %z %Y",  // "Mon Jan 02 15:04:05 -0700 2006",
-		time.RFC822:      "%d %b %y %R %Z",     // "02 Jan 06 15:04 MST",
-		time.RFC822Z:     "%d %b %y %R %z",     // "02 Jan 06 15:04 -0700",
-		time.RFC850:      "%A, %d-%b-%y %T %Z", // "Monday, 02-Jan-06 15:04:05 MST",
-		time.RFC1123:     "%a, %d %b %Y %T %Z", // "Mon, 02 Jan 2006 15:04:05 MST",
-		time.RFC1123Z:    "%a, %d %b %Y %T %z", // "Mon, 02 Jan 2006 15:04:05 -0700",
-		time.RFC3339:     "%Y-%m-%dT%T%1",      // "2006-01-02T15:04:05Z07:00", // TODO: %1 not standard
-		time.RFC3339Nano: "%Y-%m-%dT%T.%N%1",   // "2006-01-02T15:04:05.999999999Z07:00", // TODO: %1 not standard
-		time.Kitchen:     "%2:%M%p",            // "3:04PM", // TODO: %2 not standard
-		time.Stamp:       "%b %e %T",           // "Jan _2 15:04:05"
-		time.StampMilli:  "%b %e %T.%3",        // "Jan _2 15:04:05.000" // TODO: %3 not standard
-		time.StampMicro:  "%b %e %T.%4",        // "Jan _2 15:04:05.000000" // TODO: %4 not standard
-		time.StampNano:   "%b %e %T.%N",        // "Jan _2 15:04:05.000000000"
-
-		"ANSIC":       "%c",
-		"UnixDate":    "%a %b %e %T %Z %Y",  // "Mon Jan _2 15:04:05 MST 2006",
-		"RubyDate":    "%a %b %d %T %z %Y",  // "Mon Jan 02 15:04:05 -0700 2006",
-		"RFC822":      "%d %b %y %R %Z",     // "02 Jan 06 15:04 MST",
-		"RFC822Z":     "%d %b %y %R %z",     // "02 Jan 06 15:04 -0700",
-		"RFC850":      "%A, %d-%b-%y %T %Z", // "Monday, 02-Jan-06 15:04:05 MST",
-		"RFC1123":     "%a, %d %b %Y %T %Z", // "Mon, 02 Jan 2006 15:04:05 MST",
-		"RFC1123Z":    "%a, %d %b %Y %T %z", // "Mon, 02 Jan 2006 15:04:05 -0700",
-		"RFC3339":     "%Y-%m-%dT%T%1",      // "2006-01-02T15:04:05Z07:00", // TODO: %1 not standard
-		"RFC3339Nano": "%Y-%m-%dT%T.%N%1",   // "2006-01-02T15:04:05.999999999Z07:00", // TODO: %1 not standard
-		"Kitchen":     "%2:%M%p",            // "3:04PM", // TODO: %2 not standard
-		"Stamp":       "%b %e %T",           // "Jan _2 15:04:05"
-		"StampMilli":  "%b %e %T.%3",        // "Jan _2 15:04:05.000" // TODO: %3 not standard
-		"StampMicro":  "%b %e %T.%4",        // "Jan _2 15:04:05.000000" // TODO: %4 not standard
-		"StampNano":   "%b %e %T.%N",        // "Jan _2 15:04:05.000000000"
+// zoneStyles maps the values of the -zone flag to the zone style they select.
+var zoneStyles = map[string]sftgen.ZoneStyle{
+	"abbrev":   sftgen.ZoneAbbreviation,
+	"offset":   sftgen.ZoneAbbreviationOrOffset,
+	"location": sftgen.ZoneLocation,
+}
+
+// loadLocale returns the built-in locale named name, or the locale read from
+// the JSON file named name when name has a file extension.
+func loadLocale(name string) (*sftgen.Locale, error) {
+	if filepath.Ext(name) == "" {
+		return sftgen.LookupLocale(name)
+	}
+	fh, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	return sftgen.ParseLocale(fh)
+}
+
+// layouts maps the names of the layout constants of the time package to
+// their values.
+var layouts = map[string]string{
+	"ANSIC":       time.ANSIC,
+	"UnixDate":    time.UnixDate,
+	"RubyDate":    time.RubyDate,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"Kitchen":     time.Kitchen,
+	"Stamp":       time.Stamp,
+	"StampMilli":  time.StampMilli,
+	"StampMicro":  time.StampMicro,
+	"StampNano":   time.StampNano,
+}
+
+// lookupLayout returns the Go reference layout when spec is either the name
+// or the value of a layout constant of the time package, so that it is
+// scanned as a layout and written exactly like time.Format writes it.
+func lookupLayout(spec string) (string, bool) {
+	if layout, ok := layouts[spec]; ok {
+		return layout, true
+	}
+	for _, layout := range layouts {
+		if spec == layout {
+			return layout, true
+		}
 	}
+	return "", false
 }
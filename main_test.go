@@ -1,57 +1,237 @@
 package main
 
 import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
-)
 
-var when = time.Date(2006, time.January, 2, 3, 4, 5, 12345678, time.UTC)
+	"github.com/karrick/sft/sftgen"
+)
 
-// var format = time.RFC1123Z      // "%a, %d %b %Y %T %z", // "Mon, 02 Jan 2006 15:04:05 -0700"
-var format = time.RFC3339Nano // "%Y-%m-%dT%T.%N%1",   // "2006-01-02T15:04:05.999999999Z07:00"
+func TestCommandHeaderRoundTrip(t *testing.T) {
+	for _, args := range [][]string{
+		{"sft", "%F %T"},
+		{"sft", "-p", "timefmt", "-f", "formatTime", "-o", "format time.go", "%a, %d %b %Y %T %z"},
+		{"sft", "-extra", `say "%F"`},
+		{"sft", "-f", "formatCost", "$HOME costs $5 at %T"},
+		{"sft", "-locale", "", `back\slash`, "tab\there", "it's"},
+		{"sft", "-layout", "2006-01-02T15:04:05Z07:00"},
+	} {
+		header := commandHeader(args)
+		if !strings.HasPrefix(header, headerPrefix) || !strings.HasSuffix(header, "\n\n") {
+			t.Fatalf("GOT: %q; WANT: header", header)
+		}
+		got, err := splitCommand(strings.TrimSuffix(strings.TrimPrefix(header, headerPrefix), "\n\n"))
+		if err != nil {
+			t.Fatalf("%q: %s", header, err)
+		}
+		if !reflect.DeepEqual(got, args) {
+			t.Errorf("GOT: %q; WANT: %q", got, args)
+		}
+	}
 
-func TestAppendTime(t *testing.T) {
-	got := string(appendTime(nil, when))
-	want := when.Format(format)
-	if got != want {
+	// Plain arguments are not quoted, but the final argument always is.
+	if got, want := commandHeader([]string{"sft", "-p", "timefmt", "%T"}), headerPrefix+`sft -p timefmt "%T"`+"\n\n"; got != want {
 		t.Errorf("GOT: %q; WANT: %q", got, want)
 	}
 }
 
-func TestCopyTime(t *testing.T) {
-	got := string(copyTime(nil, when))
-	want := when.Format(format)
-	if got != want {
+func TestSplitCommandLegacy(t *testing.T) {
+	// Older versions wrote the final argument between double quotes,
+	// without escaping it.
+	got, err := splitCommand(`sft -f formatTime "say "%F" \o/"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"sft", "-f", "formatTime", `say "%F" \o/`}; !reflect.DeepEqual(got, want) {
 		t.Errorf("GOT: %q; WANT: %q", got, want)
 	}
+
+	for _, c := range []struct {
+		line string
+		want string
+	}{
+		{`sft "%F`, `cannot find end of quoted argument: "%F`},
+		{"", "cannot find command"},
+	} {
+		_, err = splitCommand(c.line)
+		if got := err; got == nil || got.Error() != c.want {
+			t.Errorf("GOT: %v; WANT: %q", got, c.want)
+		}
+	}
 }
 
-func BenchmarkAppendTime(b *testing.B) {
-	buf := make([]byte, 0, 512)
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		buf = appendTime(buf, when)
-		buf = buf[:0] // clear contents of byte slice
+func TestDiff(t *testing.T) {
+	var got, want []string
+	for i := 1; i <= 20; i++ {
+		line := strings.Repeat("x", i) + "\n"
+		got = append(got, line)
+		want = append(want, line)
+	}
+	got[1] = "old\n"
+	want[1] = "new\n"
+	want = append(want[:15], append([]string{"added\n"}, want[15:]...)...)
+
+	if got, want := string(diff("f.go", []byte(strings.Join(got, "")), []byte(strings.Join(want, "")))), `--- f.go
++++ f.go (regenerated)
+@@ -1,5 +1,5 @@
+ x
+-old
++new
+ xxx
+ xxxx
+ xxxxx
+@@ -13,6 +13,7 @@
+ xxxxxxxxxxxxx
+ xxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxx
++added
+ xxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxx
+`; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	if got, want := string(diff("f.go", []byte("a\nb"), []byte("a\nb\n"))), `--- f.go
++++ f.go (regenerated)
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	if got, want := string(diff("f.go", []byte("a\n"), []byte("a\n"))), "--- f.go\n+++ f.go (regenerated)\n"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	// Files that differ on too many lines to compare them all replace every
+	// line that differs.
+	old, regenerated := new(bytes.Buffer), new(bytes.Buffer)
+	for i := 0; i < 3000; i++ {
+		fmt.Fprintf(old, "old %d\n", i)
+		fmt.Fprintf(regenerated, "new %d\n", i)
+	}
+	lines := strings.Split(string(diff("f.go", old.Bytes(), regenerated.Bytes())), "\n")
+	if got, want := lines[2], "@@ -1,3000 +1,3000 @@"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+	if got, want := lines[3003], "+new 0"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
 	}
-	_ = buf
 }
 
-func BenchmarkCopyTime(b *testing.B) {
-	var silly []byte
-	buf := make([]byte, 512)
-	b.ResetTimer()
-	for i := 0; i < b.N; i++ {
-		silly = copyTime(buf, when)
-		// buf = buf[:512] // reslice byte slice
+func TestCheck(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "clock.go")
+	args := []string{"sft", "-p", "clock", "-f", "formatClock", "-o", name, "%H:%M"}
+	o, err := parseOptions(args[0], args[1:], flag.ContinueOnError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cg, err := o.generate(commandHeader(args), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb := new(bytes.Buffer)
+	if _, err = cg.WriteTo(bb); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(name, bb.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := new(bytes.Buffer)
+	if err = check(name, out); err != nil {
+		t.Errorf("GOT: %v; WANT: %v", err, nil)
+	}
+	if got, want := out.String(), ""; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	// A file edited since it was generated is stale.
+	stale := bytes.Replace(bb.Bytes(), []byte("func formatClock("), []byte("func formatClockEdited("), 1)
+	if err = os.WriteFile(name, stale, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err = check(name, out)
+	if got, want := err, name+" is not up to date"; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+	for _, want := range []string{
+		"--- " + name + "\n+++ " + name + " (regenerated)\n@@ ",
+		"\n-func formatClockEdited(buf []byte, t time.Time) []byte {\n+func formatClock(buf []byte, t time.Time) []byte {\n",
+	} {
+		if got := out.String(); !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	if err = os.WriteFile(name, []byte("package clock\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err = check(name, out)
+	if got, want := err, "cannot find generated file header in "+name; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
 	}
-	_ = silly
 }
 
-func BenchmarkStandardLibrary(b *testing.B) {
-	// b.Skip("invalid measurement unless using same time format")
-	var foo string
-	for i := 0; i < b.N; i++ {
-		foo = when.Format(format)
+func TestGenerateLayoutNames(t *testing.T) {
+	for _, c := range []struct {
+		spec, layout string
+	}{
+		{"RFC3339Nano", time.RFC3339Nano},
+		{"Kitchen", time.Kitchen},
+		{time.RFC3339, time.RFC3339},
+	} {
+		o, err := parseOptions("sft", []string{c.spec}, flag.ContinueOnError)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cg, err := o.generate("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := sftgen.NewCodeGenerator(c.layout, &sftgen.Config{FuncName: "appendTime", Layout: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := cg.String(), want.String(); got != want {
+			t.Errorf("%q: GOT: %q; WANT: %q", c.spec, got, want)
+		}
+	}
+
+	// Functions of a manifest are scanned as layouts only when they name
+	// one.
+	dir := t.TempDir()
+	manifest := filepath.Join(dir, "manifest.txt")
+	if err := os.WriteFile(manifest, []byte("formatDate %F\nformatRFC RFC3339Nano\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o, err := parseOptions("sft", []string{"-manifest", manifest}, flag.ContinueOnError)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cg, err := o.generate("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := sftgen.NewManifestCodeGenerator([]sftgen.Function{
+		{Name: "formatDate", Spec: "%F"},
+		{Name: "formatRFC", Spec: time.RFC3339Nano, Layout: true},
+	}, &sftgen.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), want.String(); got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
 	}
-	_ = foo
 }
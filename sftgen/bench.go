@@ -0,0 +1,242 @@
+package sftgen
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/karrick/sft/strftime"
+)
+
+// benchmarks returns the source code of a pair of benchmarks for each of the
+// generators, adding the packages they require to libraries. The first
+// measures the generated function, and the second measures the standard
+// library formatting the same time with the equivalent Go reference layout,
+// or is skipped when the spec has no equivalent layout.
+func benchmarks(generators []*CodeGenerator, libraries map[string]struct{}) []byte {
+	var functions []byte
+
+	for _, g := range generators {
+		name := strings.ToUpper(g.functionName[:1]) + g.functionName[1:]
+
+		when := "time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)"
+		if g.duration {
+			when = "-(26*time.Hour + 3*time.Minute + 4*time.Second + 123456789)"
+		}
+
+		var setup, call string
+		switch {
+		case g.useWriter:
+			libraries["bufio"] = struct{}{}
+			libraries["io"] = struct{}{}
+			setup = "w := bufio.NewWriter(io.Discard)"
+			call = fmt.Sprintf(`if err := %s(w, when); err != nil {
+            b.Fatal(err)
+        }`, g.functionName)
+		case g.useAppend:
+			setup = "buf := make([]byte, 0, 128)"
+			call = fmt.Sprintf("buf = %s(buf[:0], when)", g.functionName)
+		default:
+			// A function that copies returns a slice no longer than what it
+			// wrote, so it is always given the original buffer.
+			setup = "buf := make([]byte, 128)"
+			call = fmt.Sprintf("_ = %s(buf, when)", g.functionName)
+		}
+
+		appendString(&functions, `func BenchmarkGenerated%s(b *testing.B) {
+    when := %s
+    %s
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        %s
+    }
+}
+
+`, name, when, setup, call)
+
+		if g.cache != nil {
+			appendString(&functions, `func BenchmarkCached%s(b *testing.B) {
+    when := time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)
+    var formatter %s
+    buf := make([]byte, 0, 128)
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        buf = formatter.AppendFormat(buf[:0], when.Add(time.Duration(i%%1000)))
+    }
+}
+
+`, strings.ToUpper(g.cache.cacheType[:1])+g.cache.cacheType[1:], g.cache.cacheType)
+		}
+
+		if g.duration {
+			appendString(&functions, `func BenchmarkStdlib%s(b *testing.B) {
+    b.Skip("durations have no Go reference layout")
+}
+
+`, name)
+		} else if layout, ok := g.equivalentLayout(); ok {
+			appendString(&functions, `func BenchmarkStdlib%s(b *testing.B) {
+    when := time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)
+    buf := make([]byte, 0, 128)
+    b.ReportAllocs()
+    b.ResetTimer()
+    for i := 0; i < b.N; i++ {
+        buf = when.AppendFormat(buf[:0], %q)
+    }
+}
+
+`, name, layout)
+		} else {
+			appendString(&functions, `func BenchmarkStdlib%s(b *testing.B) {
+    b.Skipf("spec has no equivalent Go reference layout: %%q", %q)
+}
+
+`, name, g.spec)
+		}
+	}
+
+	return functions
+}
+
+// layoutsFromVerbs maps the formatting verbs that have an equivalent Go
+// reference layout, when written without flags or widths, to that layout.
+var layoutsFromVerbs = map[rune]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'B': "January",
+	'c': "Mon Jan _2 15:04:05 2006",
+	'd': "02",
+	'D': "01/02/06",
+	'e': "_2",
+	'F': "2006-01-02",
+	'h': "Jan",
+	'H': "15",
+	'I': "03",
+	'j': "002",
+	'm': "01",
+	'M': "04",
+	'n': "\n",
+	'p': "PM",
+	'P': "pm",
+	'r': "03:04:05 PM",
+	'R': "15:04",
+	'S': "05",
+	't': "\t",
+	'T': "15:04:05",
+	'x': "01/02/06",
+	'X': "15:04:05",
+	'y': "06",
+	'Y': "2006",
+	'z': "-0700",
+	'Z': "MST",
+	'%': "%",
+	'+': "Mon Jan _2 15:04:05 PM MST 2006",
+	'1': "Z07:00",
+	'2': "3",
+}
+
+// unpaddedLayoutsFromVerbs maps the formatting verbs that have an equivalent
+// Go reference layout when written with the '-' flag to that layout.
+var unpaddedLayoutsFromVerbs = map[rune]string{
+	'd': "2",
+	'e': "2",
+	'I': "3",
+	'm': "1",
+	'M': "4",
+	'S': "5",
+}
+
+// equivalentLayout returns the Go reference layout that writes the same text
+// as the spec of the generator, and whether there is one.
+func (cg *CodeGenerator) equivalentLayout() (string, bool) {
+	if cg.layout {
+		return cg.spec, true
+	}
+
+	var layout strings.Builder
+
+	for _, d := range cg.directives {
+		if d.verb == 0 {
+			layout.WriteString(d.literal)
+			continue
+		}
+		if d.upcase || d.swapcase {
+			return "", false
+		}
+		if !cg.locale.isEnglish() && strings.ContainsRune("aAbBchpPr+", d.verb) {
+			return "", false // time.Format only writes English names
+		}
+
+		// Fractional seconds are only layout elements when they follow a
+		// separator.
+		switch d.verb {
+		case 'N', '3', '4':
+			s := layout.String()
+			if d.pad != 0 || d.width > 9 || s == "" || (s[len(s)-1] != '.' && s[len(s)-1] != ',') {
+				return "", false // time.Format writes at most 9 digits
+			}
+			digits := 9
+			switch {
+			case d.verb == '3':
+				digits = 3
+			case d.verb == '4':
+				digits = 6
+			case d.width > 0:
+				digits = d.width
+			}
+			layout.WriteString(strings.Repeat("0", digits))
+			continue
+		}
+
+		if d.width > 0 {
+			return "", false
+		}
+		if d.colons > 0 {
+			// Only the offsets with a fixed number of fields have an
+			// equivalent layout element.
+			if d.pad != 0 || d.colons > 2 {
+				return "", false
+			}
+			layout.WriteString([...]string{1: "-07:00", 2: "-07:00:00"}[d.colons])
+			continue
+		}
+		switch d.pad {
+		case 0:
+			element, ok := layoutsFromVerbs[d.verb]
+			if !ok {
+				return "", false
+			}
+			layout.WriteString(element)
+		case '-':
+			element, ok := unpaddedLayoutsFromVerbs[d.verb]
+			if !ok {
+				return "", false
+			}
+			layout.WriteString(element)
+		default:
+			return "", false
+		}
+	}
+
+	// Literal text may itself contain layout elements, and adjacent elements
+	// may combine into different ones, so only accept the layout when it
+	// writes the same text as the spec.
+	f, err := strftime.Compile(cg.spec)
+	if err != nil {
+		return "", false
+	}
+	for _, when := range []time.Time{
+		time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC),
+		time.Date(1999, time.December, 31, 0, 59, 0, 0, time.FixedZone("XYZ", -7*3600)),
+		time.Date(2024, time.October, 13, 9, 8, 7, 6, time.FixedZone("ABC", 5*3600+30*60)),
+	} {
+		if f.Format(when) != when.Format(layout.String()) {
+			return "", false
+		}
+	}
+
+	return layout.String(), true
+}
@@ -0,0 +1,66 @@
+package sftgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewCodeGeneratorEmitBench(t *testing.T) {
+	cg, err := NewCodeGenerator("%F %T", &Config{EmitBench: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb := new(bytes.Buffer)
+	if _, err = cg.WriteTestTo(bb); err != nil {
+		t.Fatal(err)
+	}
+	got := bb.String()
+	for _, want := range []string{
+		"func BenchmarkGeneratedFormatTime(b *testing.B) {\n",
+		"func BenchmarkStdlibFormatTime(b *testing.B) {\n",
+		`when.AppendFormat(buf[:0], "2006-01-02 15:04:05")`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+	if want := "func FuzzFormatTime"; strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: no %q", got, want)
+	}
+}
+
+func TestEquivalentLayout(t *testing.T) {
+	cases := []struct {
+		spec   string
+		layout string // empty when there is no equivalent layout
+	}{
+		{"%F %T", "2006-01-02 15:04:05"},
+		{"%a, %d %b %Y %T %z", "Mon, 02 Jan 2006 15:04:05 -0700"},
+		{"%Y-%m-%dT%T.%N%1", "2006-01-02T15:04:05.000000000Z07:00"},
+		{"%b %e %T.%3N", "Jan _2 15:04:05.000"},
+		{"%2:%M%p", "3:04PM"},
+		{"%-m/%-d", "1/2"},
+		{"%FT%T%:z", "2006-01-02T15:04:05-07:00"},
+		{"%:::z", ""},
+		{"%G-W%V", ""},
+		{"%^a", ""},
+		{"%5d", ""},
+		{"%N", ""},
+		{"Monday %F", ""}, // the literal text is a layout element
+	}
+
+	for _, c := range cases {
+		cg, err := NewCodeGenerator(c.spec, &Config{AllowExtra: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := cg.equivalentLayout()
+		if want := c.layout != ""; ok != want {
+			t.Errorf("%q: GOT: %v; WANT: %v", c.spec, ok, want)
+		}
+		if got != c.layout {
+			t.Errorf("%q: GOT: %q; WANT: %q", c.spec, got, c.layout)
+		}
+	}
+}
@@ -0,0 +1,665 @@
+package sftgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/printer"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// code is a sequence of statements of an emitted function. The writers of the
+// formatting function and the readers of the parse function build it with the
+// constructors below, so that the emitted functions are assembled, checked,
+// and printed as syntax trees rather than as text.
+type code []ast.Stmt
+
+// ident returns the identifier name. Every use of a name is a new identifier,
+// because printFunction positions the nodes of each statement.
+func ident(name string) *ast.Ident {
+	return ast.NewIdent(name)
+}
+
+// sel returns the identifier name, or when name has dots, such as "time.UTC",
+// the selector expression of its identifiers.
+func sel(name string) ast.Expr {
+	parts := strings.Split(name, ".")
+	var x ast.Expr = ident(parts[0])
+	for _, part := range parts[1:] {
+		x = &ast.SelectorExpr{X: x, Sel: ident(part)}
+	}
+	return x
+}
+
+// intLit returns the integer literal of v, negated when v is negative.
+func intLit(v int) ast.Expr {
+	if v < 0 {
+		return unary(token.SUB, intLit(-v))
+	}
+	return &ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(v)}
+}
+
+// strLit returns the string literal of s.
+func strLit(s string) ast.Expr {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(s)}
+}
+
+// charLit returns the character literal of c.
+func charLit(c byte) ast.Expr {
+	return &ast.BasicLit{Kind: token.CHAR, Value: strconv.QuoteRune(rune(c))}
+}
+
+// binary returns the binary expression x op y. The printer does not add
+// parentheses, so an operand of lower precedence than op must be wrapped by
+// paren.
+func binary(x ast.Expr, op token.Token, y ast.Expr) ast.Expr {
+	return &ast.BinaryExpr{X: x, Op: op, Y: y}
+}
+
+// unary returns the unary expression op x.
+func unary(op token.Token, x ast.Expr) ast.Expr {
+	return &ast.UnaryExpr{Op: op, X: x}
+}
+
+// paren returns x in parentheses.
+func paren(x ast.Expr) ast.Expr {
+	return &ast.ParenExpr{X: x}
+}
+
+// and returns the conditional and of all of the conds.
+func and(conds ...ast.Expr) ast.Expr {
+	return chain(token.LAND, conds)
+}
+
+// or returns the conditional or of all of the conds.
+func or(conds ...ast.Expr) ast.Expr {
+	return chain(token.LOR, conds)
+}
+
+// chain returns the left associative chain of the operator op between all of
+// the operands. An operand that is itself a chain of op is spliced into it,
+// because the printer parenthesizes such a chain on the right of op.
+func chain(op token.Token, operands []ast.Expr) ast.Expr {
+	var x ast.Expr
+	for _, y := range operands {
+		if b, ok := y.(*ast.BinaryExpr); ok && b.Op == op && x != nil {
+			x = chain(op, []ast.Expr{x, b.X, b.Y})
+			continue
+		}
+		if x == nil {
+			x = y
+		} else {
+			x = binary(x, op, y)
+		}
+	}
+	return x
+}
+
+// plus returns the expression that adds n to the variable name, which is the
+// variable itself when n is zero.
+func plus(name string, n int) ast.Expr {
+	switch {
+	case n > 0:
+		return binary(ident(name), token.ADD, intLit(n))
+	case n < 0:
+		return binary(ident(name), token.SUB, intLit(-n))
+	}
+	return ident(name)
+}
+
+// call returns the call of the function fun, which is named like the names
+// of sel, with args.
+func call(fun string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: sel(fun), Args: args}
+}
+
+// spread returns the call of the function fun like call, with the final
+// argument passed to its variadic parameter, as in append(buf, s...).
+func spread(fun string, args ...ast.Expr) *ast.CallExpr {
+	c := call(fun, args...)
+	// The printer writes the ellipsis only when it has a valid position,
+	// which printFunction moves to the line of its statement.
+	c.Ellipsis = token.Pos(1)
+	return c
+}
+
+// method returns the call of the method name of x with args.
+func method(x ast.Expr, name string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{Fun: &ast.SelectorExpr{X: x, Sel: ident(name)}, Args: args}
+}
+
+// indexExpr returns the index expression x[i].
+func indexExpr(x, i ast.Expr) ast.Expr {
+	return &ast.IndexExpr{X: x, Index: i}
+}
+
+// sliceExpr returns the slice expression x[lo:hi], where either bound may be
+// nil.
+func sliceExpr(x, lo, hi ast.Expr) ast.Expr {
+	return &ast.SliceExpr{X: x, Low: lo, High: hi}
+}
+
+// arrayOf returns the array type of n elements of type elt, the slice type
+// when n is nil, or the array type whose length is that of its literal when
+// n is an *ast.Ellipsis.
+func arrayOf(n, elt ast.Expr) ast.Expr {
+	return &ast.ArrayType{Len: n, Elt: elt}
+}
+
+// compositeLit returns the composite literal of type typ with elts.
+func compositeLit(typ ast.Expr, elts ...ast.Expr) ast.Expr {
+	return &ast.CompositeLit{Type: typ, Elts: elts}
+}
+
+// assign returns the assignment lhs tok rhs, where tok is token.ASSIGN,
+// token.DEFINE, or an operator assignment such as token.ADD_ASSIGN.
+func assign(lhs ast.Expr, tok token.Token, rhs ast.Expr) code {
+	return assignList([]ast.Expr{lhs}, tok, []ast.Expr{rhs})
+}
+
+// assignList returns the assignment of all of rhs to all of lhs.
+func assignList(lhs []ast.Expr, tok token.Token, rhs []ast.Expr) code {
+	return code{&ast.AssignStmt{Lhs: lhs, Tok: tok, Rhs: rhs}}
+}
+
+// define returns the short variable declaration name := value.
+func define(name string, value ast.Expr) code {
+	return assign(ident(name), token.DEFINE, value)
+}
+
+// idents returns the identifiers of names.
+func idents(names ...string) []ast.Expr {
+	ids := make([]ast.Expr, len(names))
+	for i, name := range names {
+		ids[i] = ident(name)
+	}
+	return ids
+}
+
+// incDec returns the statement x++ when tok is token.INC, and x-- when it is
+// token.DEC.
+func incDec(x ast.Expr, tok token.Token) code {
+	return code{&ast.IncDecStmt{X: x, Tok: tok}}
+}
+
+// exprStmt returns the statement that evaluates x.
+func exprStmt(x ast.Expr) code {
+	return code{&ast.ExprStmt{X: x}}
+}
+
+// returnStmt returns the return statement of results.
+func returnStmt(results ...ast.Expr) code {
+	return code{&ast.ReturnStmt{Results: results}}
+}
+
+// breakStmt returns the break statement.
+func breakStmt() code {
+	return code{&ast.BranchStmt{Tok: token.BREAK}}
+}
+
+// continueStmt returns the continue statement.
+func continueStmt() code {
+	return code{&ast.BranchStmt{Tok: token.CONTINUE}}
+}
+
+// varDecl returns the declaration of the variables names of type typ.
+func varDecl(typ ast.Expr, names ...string) code {
+	spec := &ast.ValueSpec{Type: typ}
+	for _, name := range names {
+		spec.Names = append(spec.Names, ident(name))
+	}
+	return code{&ast.DeclStmt{Decl: &ast.GenDecl{Tok: token.VAR, Specs: []ast.Spec{spec}}}}
+}
+
+// valueDecl returns the declaration of the constant or variable name, as
+// selected by tok, initialized to value. It is valid both inside a function
+// and at package level.
+func valueDecl(tok token.Token, name string, value ast.Expr) *ast.GenDecl {
+	spec := &ast.ValueSpec{Names: []*ast.Ident{ident(name)}, Values: []ast.Expr{value}}
+	return &ast.GenDecl{Tok: tok, Specs: []ast.Spec{spec}}
+}
+
+// ifElse returns an if statement that runs then when cond is true, and els
+// otherwise. When els is a single if statement, it is chained as an else if.
+func ifElse(cond ast.Expr, then, els code) code {
+	return ifInit(nil, cond, then, els)
+}
+
+// ifInit returns an if statement like ifElse, that first runs the simple
+// statement init, when it is not nil.
+func ifInit(init code, cond ast.Expr, then, els code) code {
+	s := &ast.IfStmt{Cond: cond, Body: &ast.BlockStmt{List: then}}
+	if len(init) > 0 {
+		s.Init = init[0]
+	}
+	if len(els) == 1 {
+		if elseIf, ok := els[0].(*ast.IfStmt); ok {
+			s.Else = elseIf
+			return code{s}
+		}
+	}
+	if len(els) > 0 {
+		s.Else = &ast.BlockStmt{List: els}
+	}
+	return code{s}
+}
+
+// forStmt returns the for statement that runs body while cond is true, after
+// running the simple statement init, and running post after each iteration.
+// Any of init, cond, and post may be nil.
+func forStmt(init code, cond ast.Expr, post code, body code) code {
+	s := &ast.ForStmt{Cond: cond, Body: &ast.BlockStmt{List: body}}
+	if len(init) > 0 {
+		s.Init = init[0]
+	}
+	if len(post) > 0 {
+		s.Post = post[0]
+	}
+	return code{s}
+}
+
+// rangeStmt returns the for statement that runs body for every key and value
+// of x, which are declared by it.
+func rangeStmt(key, value string, x ast.Expr, body code) code {
+	s := &ast.RangeStmt{Key: ident(key), Tok: token.DEFINE, X: x, Body: &ast.BlockStmt{List: body}}
+	if value != "" {
+		s.Value = ident(value)
+	}
+	return code{s}
+}
+
+// switchStmt returns the switch statement on tag, which may be nil, with the
+// clauses returned by caseClause.
+func switchStmt(tag ast.Expr, clauses ...code) code {
+	return code{&ast.SwitchStmt{Tag: tag, Body: &ast.BlockStmt{List: join(clauses...)}}}
+}
+
+// caseClause returns the case clause that runs body when the tag of its
+// switch statement matches one of list, or the default clause when list is
+// empty.
+func caseClause(list []ast.Expr, body code) code {
+	return code{&ast.CaseClause{List: list, Body: body}}
+}
+
+// join returns the statements of all of the parts, in order.
+func join(parts ...code) code {
+	var c code
+	for _, p := range parts {
+		c = append(c, p...)
+	}
+	return c
+}
+
+// block returns body as a block statement, which scopes the variables it
+// declares.
+func block(body ...code) code {
+	return code{&ast.BlockStmt{List: join(body...)}}
+}
+
+// note returns a marker that is printed as a comment with the text formatted
+// according to format, when annotating, and that is otherwise not printed.
+// The comment typically names the writer that emitted the code that follows.
+// A note without text is always printed as a blank line.
+func note(format string, a ...interface{}) code {
+	if format == "" {
+		return code{&ast.EmptyStmt{Implicit: true}}
+	}
+	return code{&ast.ExprStmt{X: &ast.BasicLit{Kind: token.COMMENT, Value: "// " + fmt.Sprintf(format, a...)}}}
+}
+
+// section returns a blank line followed by a note, which separates the code
+// emitted for a formatting verb from the code before it.
+func section(format string, a ...interface{}) code {
+	return join(note(""), note(format, a...))
+}
+
+// isSymbol returns true when name is a symbol returned by gensym.
+func isSymbol(name string) bool {
+	if !strings.HasPrefix(name, "gs") || len(name) == 2 {
+		return false
+	}
+	_, err := strconv.Atoi(name[2:])
+	return err == nil
+}
+
+// referenced returns those of names that are referenced by body, in the
+// order of names.
+func referenced(body code, names ...string) []string {
+	found := make(map[string]bool)
+	ast.Inspect(&ast.BlockStmt{List: body}, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			found[id.Name] = true
+		}
+		return true
+	})
+
+	var refs []string
+	for _, name := range names {
+		if found[name] {
+			refs = append(refs, name)
+		}
+	}
+	return refs
+}
+
+// eliminateDeadSymbols replaces the symbols that are initialized by the
+// statements of body, but never used, with blank identifiers, and removes
+// the statements that no longer initialize any variable. Because removing a
+// statement may leave the symbols it used unused, it repeats until every
+// remaining symbol is used.
+func eliminateDeadSymbols(body code) code {
+	for {
+		uses := make(map[string]int)
+		ast.Inspect(&ast.BlockStmt{List: body}, func(n ast.Node) bool {
+			if id, ok := n.(*ast.Ident); ok && isSymbol(id.Name) {
+				uses[id.Name]++
+			}
+			return true
+		})
+
+		var changed bool
+		kept := make(code, 0, len(body))
+		for _, s := range body {
+			as, ok := s.(*ast.AssignStmt)
+			if !ok || as.Tok != token.DEFINE {
+				kept = append(kept, s)
+				continue
+			}
+			var live bool
+			for _, lhs := range as.Lhs {
+				id := lhs.(*ast.Ident)
+				if isSymbol(id.Name) && uses[id.Name] == 1 { // only its initialization
+					id.Name = "_"
+					changed = true
+				}
+				if id.Name != "_" {
+					live = true
+				}
+			}
+			if !live {
+				changed = true
+				continue
+			}
+			kept = append(kept, s)
+		}
+		body = kept
+
+		if !changed {
+			return body
+		}
+	}
+}
+
+// checkSymbols returns an error when body uses a symbol returned by gensym
+// before a statement of body initializes it.
+func checkSymbols(body code) error {
+	initialized := make(map[string]struct{})
+	for _, s := range body {
+		var err error
+		ast.Inspect(s, func(n ast.Node) bool {
+			if as, ok := n.(*ast.AssignStmt); ok && as.Tok == token.DEFINE {
+				// The initializations are only walked here, so that their
+				// symbols are recorded after the values they use.
+				for _, rhs := range as.Rhs {
+					ast.Inspect(rhs, func(n ast.Node) bool {
+						if id, ok := n.(*ast.Ident); ok && isSymbol(id.Name) && err == nil {
+							if _, ok := initialized[id.Name]; !ok {
+								err = fmt.Errorf("cannot find initialization for %q", id.Name)
+							}
+						}
+						return true
+					})
+				}
+				for _, lhs := range as.Lhs {
+					if id, ok := lhs.(*ast.Ident); ok && isSymbol(id.Name) {
+						initialized[id.Name] = struct{}{}
+					}
+				}
+				return false
+			}
+			if id, ok := n.(*ast.Ident); ok && isSymbol(id.Name) && err == nil {
+				if _, ok := initialized[id.Name]; !ok {
+					err = fmt.Errorf("cannot find initialization for %q", id.Name)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// field returns the parameter or result name of type typ, which is unnamed
+// when name is empty.
+func field(name string, typ ast.Expr) *ast.Field {
+	f := &ast.Field{Type: typ}
+	if name != "" {
+		f.Names = []*ast.Ident{ident(name)}
+	}
+	return f
+}
+
+// funcDecl returns the declaration of the function name with params,
+// results, and body.
+func funcDecl(name string, params, results []*ast.Field, body code) *ast.FuncDecl {
+	typ := &ast.FuncType{Params: &ast.FieldList{List: params}}
+	if len(results) > 0 {
+		typ.Results = &ast.FieldList{List: results}
+	}
+	return &ast.FuncDecl{Name: ident(name), Type: typ, Body: &ast.BlockStmt{List: body}}
+}
+
+// printFunction returns the source code of the function declared by decl.
+// The notes of its body are printed as blank lines, and as comments when
+// annotate is true.
+func printFunction(decl *ast.FuncDecl, annotate bool) ([]byte, error) {
+	fset := token.NewFileSet()
+	l := &layout{base: fset.Base(), line: 1, annotate: annotate}
+	decl.Type.Func = l.pos()
+	l.block(decl.Body, l.pos())
+
+	lines := make([]int, l.line)
+	for i := range lines {
+		lines[i] = i * lineWidth
+	}
+	fset.AddFile("", l.base, l.line*lineWidth).SetLines(lines)
+
+	bb := new(bytes.Buffer)
+	if err := format.Node(bb, fset, &printer.CommentedNode{Node: decl, Comments: l.comments}); err != nil {
+		return nil, err
+	}
+	bb.WriteString("\n\n")
+	return bb.Bytes(), nil
+}
+
+// lineWidth is the number of bytes between the positions of consecutive lines
+// assigned by layout. The printer estimates the positions of the nodes that
+// have none by counting the bytes it writes, so lines are spaced further
+// apart than any of them is long, which keeps a comment from being printed
+// before the end of the line preceding it.
+const lineWidth = 1 << 16
+
+// layout assigns the lines of the statements of a function body, like
+// go/printer assigns the lines of the source it prints, so that the printer
+// breaks lines where the notes of the body ask for blank lines and comments.
+// Only the leftmost token of each statement is positioned.
+type layout struct {
+	base     int
+	line     int
+	annotate bool
+	comments []*ast.CommentGroup
+
+	// blank is true while a blank line is to be printed before the next
+	// line, and open is true until a line follows the opening brace of a
+	// block, where blank lines are not printed.
+	blank, open bool
+}
+
+// pos returns the position of the current line.
+func (l *layout) pos() token.Pos {
+	return token.Pos(l.base + (l.line-1)*lineWidth)
+}
+
+// next moves to the next line, after the pending blank line, and returns its
+// position.
+func (l *layout) next() token.Pos {
+	if l.blank {
+		l.line++
+		l.blank = false
+	}
+	l.line++
+	l.open = false
+	return l.pos()
+}
+
+// block lays out the block b, whose opening brace is at pos.
+func (l *layout) block(b *ast.BlockStmt, pos token.Pos) {
+	b.Lbrace = pos
+	l.open = true
+	b.List = l.stmts(b.List)
+	l.blank, l.open = false, false
+	l.line++
+	b.Rbrace = l.pos()
+}
+
+// stmts lays out list, and returns it without its notes, whose comments are
+// recorded when annotating.
+func (l *layout) stmts(list []ast.Stmt) []ast.Stmt {
+	kept := list[:0]
+	for _, s := range list {
+		switch s := s.(type) {
+		case *ast.EmptyStmt:
+			if !l.open {
+				l.blank = true
+			}
+			continue
+		case *ast.ExprStmt:
+			if lit, ok := s.X.(*ast.BasicLit); ok && lit.Kind == token.COMMENT {
+				if l.annotate {
+					comment := &ast.Comment{Slash: l.next(), Text: lit.Value}
+					l.comments = append(l.comments, &ast.CommentGroup{List: []*ast.Comment{comment}})
+				}
+				continue
+			}
+		}
+		l.stmt(s)
+		kept = append(kept, s)
+	}
+	return kept
+}
+
+// stmt lays out the statement s on the next line, along with the statements
+// it contains.
+func (l *layout) stmt(s ast.Stmt) {
+	pos := l.next()
+	ast.Inspect(s, func(n ast.Node) bool {
+		// The ellipses of the statements that s contains are moved again
+		// when they are laid out.
+		if c, ok := n.(*ast.CallExpr); ok && c.Ellipsis.IsValid() {
+			c.Ellipsis = pos
+		}
+		return true
+	})
+
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		s.Lhs[0] = leftmost(s.Lhs[0], pos)
+	case *ast.IncDecStmt:
+		s.X = leftmost(s.X, pos)
+	case *ast.ExprStmt:
+		s.X = leftmost(s.X, pos)
+	case *ast.DeclStmt:
+		s.Decl.(*ast.GenDecl).TokPos = pos
+	case *ast.ReturnStmt:
+		s.Return = pos
+	case *ast.BranchStmt:
+		s.TokPos = pos
+	case *ast.BlockStmt:
+		l.block(s, pos)
+	case *ast.IfStmt:
+		l.ifStmt(s, pos)
+	case *ast.ForStmt:
+		s.For = pos
+		l.block(s.Body, pos)
+	case *ast.RangeStmt:
+		s.For = pos
+		l.block(s.Body, pos)
+	case *ast.SwitchStmt:
+		s.Switch = pos
+		l.block(s.Body, pos)
+	case *ast.CaseClause:
+		// Unlike after an opening brace, a blank line may follow the colon
+		// of a case clause.
+		s.Case = pos
+		s.Body = l.stmts(s.Body)
+	default:
+		panic(fmt.Errorf("cannot lay out statement of type %T", s))
+	}
+}
+
+// ifStmt lays out the if statement s at pos, followed by its else branch on
+// the line of its closing brace.
+func (l *layout) ifStmt(s *ast.IfStmt, pos token.Pos) {
+	s.If = pos
+	l.block(s.Body, pos)
+	switch els := s.Else.(type) {
+	case *ast.IfStmt:
+		l.ifStmt(els, s.Body.Rbrace)
+	case *ast.BlockStmt:
+		l.block(els, s.Body.Rbrace)
+	}
+}
+
+// leftmost returns a copy of x whose leftmost token is at pos. It copies the
+// nodes that it positions, rather than modifying them, so that a node shared
+// by several statements keeps no position.
+func leftmost(x ast.Expr, pos token.Pos) ast.Expr {
+	switch x := x.(type) {
+	case *ast.Ident:
+		c := *x
+		c.NamePos = pos
+		return &c
+	case *ast.BasicLit:
+		c := *x
+		c.ValuePos = pos
+		return &c
+	case *ast.ParenExpr:
+		c := *x
+		c.Lparen = pos
+		return &c
+	case *ast.UnaryExpr:
+		c := *x
+		c.OpPos = pos
+		return &c
+	case *ast.StarExpr:
+		c := *x
+		c.Star = pos
+		return &c
+	case *ast.BinaryExpr:
+		c := *x
+		c.X = leftmost(x.X, pos)
+		return &c
+	case *ast.CallExpr:
+		c := *x
+		c.Fun = leftmost(x.Fun, pos)
+		return &c
+	case *ast.SelectorExpr:
+		c := *x
+		c.X = leftmost(x.X, pos)
+		return &c
+	case *ast.IndexExpr:
+		c := *x
+		c.X = leftmost(x.X, pos)
+		return &c
+	case *ast.SliceExpr:
+		c := *x
+		c.X = leftmost(x.X, pos)
+		return &c
+	}
+	panic(fmt.Errorf("cannot position expression of type %T", x))
+}
@@ -0,0 +1,83 @@
+package sftgen
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func printCode(t *testing.T, c code) string {
+	t.Helper()
+	bb := new(bytes.Buffer)
+	for _, s := range c {
+		if err := format.Node(bb, token.NewFileSet(), s); err != nil {
+			t.Fatal(err)
+		}
+		bb.WriteString("\n")
+	}
+	return bb.String()
+}
+
+func TestEliminateDeadSymbols(t *testing.T) {
+	body := join(
+		assignList(idents("gs0", "gs1", "gs2"), token.DEFINE, []ast.Expr{call("t.Date")}),
+		define("gs3", binary(ident("gs0"), token.QUO, intLit(100))),
+		define("gs4", plus("gs3", 1)),
+		appendBytes(indexExpr(ident("digits"), ident("gs2"))),
+	)
+
+	got := printCode(t, eliminateDeadSymbols(body))
+	if want := "_, _, gs2 := t.Date()\nbuf = append(buf, digits[gs2])\n"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+}
+
+func TestCheckSymbols(t *testing.T) {
+	use := appendBytes(indexExpr(ident("digits"), ident("gs0")))
+
+	err := checkSymbols(join(define("gs0", call("t.Nanosecond")), use))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = checkSymbols(join(define("gs0", binary(ident("gs1"), token.QUO, intLit(1000))), use))
+	if got, want := err, `cannot find initialization for "gs1"`; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+}
+
+func TestNewCodeGeneratorAnnotate(t *testing.T) {
+	cg, err := NewCodeGenerator("%a %T", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	if body := got[strings.Index(got, "func formatTime("):]; strings.Contains(body, "//") {
+		t.Errorf("GOT: %q; WANT: no comments", body)
+	}
+	// The end of a fixed width name is not used when writing at offsets
+	// known while generating code.
+	if strings.Contains(got, " + 3\n") {
+		t.Errorf("GOT: %q; WANT: no unused symbol", got)
+	}
+
+	cg, err = NewCodeGenerator("%a %T", &Config{Annotate: true, ParseFuncName: "parseTime"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = cg.String()
+	for _, want := range []string{
+		"\t// Weekday Short codegen offset\n",
+		"\t// writeTC\n",
+		"\t// write2DigitsZero codegen offset\n",
+		"\t// readWeekdayShort\n",
+		"\t// readDigits zero padded\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package sftgen
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strings"
+)
+
+// newCacheGenerator returns the generator of the function that fills the
+// cache of the caching formatter type. It formats the directives of cg
+// without their fractional seconds, and records the byte offsets where they
+// were omitted, so that only the fractional seconds need to be written while
+// the Unix second of the formatted times does not change.
+func (cg *CodeGenerator) newCacheGenerator(typeName string, sync bool) (*CodeGenerator, error) {
+	if !token.IsIdentifier(typeName) {
+		return nil, fmt.Errorf("cannot use %q as type name", typeName)
+	}
+	if cg.layout {
+		return nil, errors.New("cannot use cache with Go reference layouts")
+	}
+	for _, d := range cg.directives {
+		// The cache writes a fixed number of fractional second digits.
+		if d.verb == 'N' && (d.pad == '-' || d.pad == '_') {
+			return nil, fmt.Errorf("cannot use cache with flag %q of format verb %q at index %d", d.pad, d.verb, d.index)
+		}
+	}
+
+	cc := &CodeGenerator{
+		valuesFromInit: make(map[string]*returnValues),
+		initFromSymbol: make(map[string]string),
+		libraries:      make(map[string]struct{}),
+		spec:           cg.spec,
+		functionName:   "fill" + strings.ToUpper(typeName[:1]) + typeName[1:],
+		allowExtra:     cg.allowExtra,
+		useAppend:      true,
+		annotate:       cg.annotate,
+		digitPairs:     cg.digitPairs,
+		zoneStyle:      cg.zoneStyle,
+		locale:         cg.locale,
+		directives:     cg.directives,
+		cacheType:      typeName,
+		cacheSync:      sync,
+		cacheFill:      true,
+	}
+
+	var err error
+	if cc.formatSource, err = cc.scan(); err != nil {
+		return nil, err
+	}
+	if sync {
+		cg.libraries["sync/atomic"] = struct{}{}
+	}
+	for p := range cc.libraries {
+		cg.libraries[p] = struct{}{}
+	}
+	return cc, nil
+}
+
+// fractionDigits returns the number of fractional second digits that the
+// directive writes, or 0 when it does not write fractional seconds. A field
+// width wider than the 9 digits of a nanosecond is padded by
+// fractionPadding.
+func (d directive) fractionDigits() int {
+	switch d.verb {
+	case 'N':
+		if d.width > 0 && d.width < 9 {
+			return d.width
+		}
+		return 9
+	case '3':
+		return 3
+	case '4':
+		return 6
+	}
+	return 0
+}
+
+// fractionPadding returns the text that follows the 9 digits of a %N
+// directive with a wider field width. Like GNU, it pads the field on the
+// right with zeros, with spaces for the '_' flag, and not at all for the '-'
+// flag.
+func (d directive) fractionPadding() string {
+	if d.verb != 'N' || d.width <= 9 {
+		return ""
+	}
+	switch d.pad {
+	case '-':
+		return ""
+	case '_':
+		return strings.Repeat(" ", d.width-9)
+	}
+	return strings.Repeat("0", d.width-9)
+}
+
+// writeCut records the offset of the fractional seconds that the function
+// filling the cache omits, and the number of digits to write there.
+func (cg *CodeGenerator) writeCut(digits int) code {
+	cg.cutDigits = append(cg.cutDigits, digits)
+	return join(note("writeCut %d fractional second digits", digits), assign(indexExpr(ident("cuts"), intLit(len(cg.cutDigits)-1)), token.ASSIGN, lenOf("buf")))
+}
+
+// cacheSource returns the source code of the caching formatter type, its
+// AppendFormat method, and the function that fills its cache. The filled
+// cache holds the text of the spec for one Unix second and location, without
+// its fractional seconds.
+func (cc *CodeGenerator) cacheSource(formatFunction string, hoisted bool) ([]byte, error) {
+	fill, err := cc.function(hoisted)
+	if err != nil {
+		return nil, err
+	}
+
+	name := cc.cacheType
+	cuts := len(cc.cutDigits)
+	dest := make([]byte, 0, 4096+len(fill))
+
+	// Without fractional seconds, the cached text is the formatted time.
+	field, assign, bind := "", "%[1]s.text", "text := %[1]s.text"
+	if cuts > 0 {
+		field = fmt.Sprintf("\n    cuts [%d]int", cuts)
+		assign, bind = "%[1]s.text, %[1]s.cuts", "text, cuts := %[1]s.text, %[1]s.cuts"
+	}
+
+	if cc.cacheSync {
+		entry := strings.ToLower(name[:1]) + name[1:] + "Entry"
+		appendString(&dest, `// %s formats times like %s, but reuses the text it formatted for the
+// previous Unix second and location, so that only the fractional seconds are
+// formatted while they do not change. The zero value is ready to use. It is
+// safe for concurrent use; a time in a different second replaces the cached
+// text without blocking other goroutines.
+type %s struct {
+    entry atomic.Value // *%s
+}
+
+// %s holds the text formatted for one Unix second and location. It is never
+// modified once it is stored.
+type %s struct {
+    sec  int64
+    loc  *time.Location
+    text []byte%s
+}
+
+// AppendFormat appends t formatted according to %q to buf.
+func (f *%s) AppendFormat(buf []byte, t time.Time) []byte {
+    sec, loc := t.Unix(), t.Location()
+    e, _ := f.entry.Load().(*%s)
+    if e == nil || e.sec != sec || e.loc != loc {
+        e = &%s{sec: sec, loc: loc}
+        %s = %s(nil, t)
+        f.entry.Store(e)
+    }
+    %s
+`, name, formatFunction, name, entry, entry, entry, field, cc.spec, name, entry, entry, fmt.Sprintf(assign, "e"), cc.functionName, fmt.Sprintf(bind, "e"))
+	} else {
+		appendString(&dest, `// %s formats times like %s, but reuses the text it formatted for the
+// previous Unix second and location, so that only the fractional seconds are
+// formatted while they do not change. The zero value is ready to use. It is
+// not safe for concurrent use; each goroutine should use its own.
+type %s struct {
+    sec  int64
+    loc  *time.Location
+    text []byte%s
+}
+
+// AppendFormat appends t formatted according to %q to buf.
+func (f *%s) AppendFormat(buf []byte, t time.Time) []byte {
+    if sec, loc := t.Unix(), t.Location(); f.loc != loc || f.sec != sec {
+        f.sec, f.loc = sec, loc
+        %s = %s(f.text[:0], t)
+    }
+    %s
+`, name, formatFunction, name, field, cc.spec, name, fmt.Sprintf(assign, "f"), cc.functionName, fmt.Sprintf(bind, "f"))
+	}
+
+	if cuts == 0 {
+		appendString(&dest, "    return append(buf, text...)\n}\n\n")
+		return append(dest, fill...), nil
+	}
+
+	appendString(&dest, "    nanos := t.Nanosecond()\n")
+	for i, digits := range cc.cutDigits {
+		var from string
+		if i > 0 {
+			from = fmt.Sprintf("cuts[%d]", i-1)
+		}
+		appendString(&dest, "\n    buf = append(buf, text[%s:cuts[%d]]...)\n", from, i)
+		if digits == 9 {
+			appendString(&dest, "    frac%d := nanos\n", i)
+		} else {
+			appendString(&dest, "    frac%d := nanos / %d\n", i, pow10(9-digits))
+		}
+		appendString(&dest, "    buf = append(buf, %s)\n", fractionBytes(fmt.Sprintf("frac%d", i), digits))
+	}
+	appendString(&dest, "    return append(buf, text[cuts[%d]:]...)\n}\n\n", cuts-1)
+
+	return append(dest, fill...), nil
+}
+
+// fractionBytes returns the comma separated byte expressions of the digits
+// of value, which has digits decimal digits.
+func fractionBytes(value string, digits int) string {
+	exprs := make([]string, digits)
+	for i := range exprs {
+		divisor := pow10(digits - 1 - i)
+		switch {
+		case digits == 1:
+			exprs[i] = fmt.Sprintf("byte('0' + %s)", value)
+		case i == 0:
+			exprs[i] = fmt.Sprintf("byte('0' + %s/%d)", value, divisor)
+		case divisor == 1:
+			exprs[i] = fmt.Sprintf("byte('0' + %s%%10)", value)
+		default:
+			exprs[i] = fmt.Sprintf("byte('0' + %s/%d%%10)", value, divisor)
+		}
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func pow10(n int) int {
+	p := 1
+	for ; n > 0; n-- {
+		p *= 10
+	}
+	return p
+}
@@ -0,0 +1,67 @@
+package sftgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewCodeGeneratorCache(t *testing.T) {
+	cg, err := NewCodeGenerator("%F %T.%3N %z", &Config{CacheType: "TimeFormatter"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"// TimeFormatter formats times like formatTime, but reuses the text it formatted for the\n",
+		"// AppendFormat appends t formatted according to \"%F %T.%3N %z\" to buf.\nfunc (f *TimeFormatter) AppendFormat(buf []byte, t time.Time) []byte {\n",
+		"func fillTimeFormatter(buf []byte, t time.Time) ([]byte, [1]int) {\n",
+		"f.text, f.cuts = fillTimeFormatter(f.text[:0], t)\n",
+		"buf = append(buf, byte('0'+frac0/100), byte('0'+frac0/10%10), byte('0'+frac0%10))\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	cg, err = NewCodeGenerator("%c", &Config{CacheType: "stamp", CacheSync: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = cg.String()
+	for _, want := range []string{
+		"\"sync/atomic\"\n",
+		"entry atomic.Value // *stampEntry\n",
+		"// stampEntry holds the text formatted for one Unix second and location. It is never\n",
+		"e.text = fillStamp(nil, t)\n",
+		"return append(buf, text...)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	// The padding of %N beyond 9 digits is cached with the text that
+	// follows the fractional seconds.
+	cg, err = NewCodeGenerator("%T.%12N", &Config{CacheType: "TimeFormatter"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), "cuts[0] = len(buf)\n\tbuf = append(buf, \"000\"...)\n"; !strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	_, err = NewCodeGenerator("%F", &Config{CacheType: "time formatter"})
+	if got, want := err, `cannot use "time formatter" as type name`; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+
+	_, err = NewCodeGenerator("15:04:05.000", &Config{Layout: true, CacheType: "TimeFormatter"})
+	if got, want := err, "cannot use cache with Go reference layouts"; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+
+	_, err = NewCodeGenerator("%T.%_3N", &Config{CacheType: "TimeFormatter"})
+	if got, want := err, "cannot use cache with flag '_' of format verb 'N' at index 6"; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+}
@@ -0,0 +1,2788 @@
+// Package sftgen generates Go source code that formats time.Time values
+// according to a strftime(3) style time format spec, or a Go reference
+// layout.
+//
+// The sft command is a thin wrapper around this package; other programs may
+// use it to embed generated formatting functions in their own output without
+// invoking sft.
+package sftgen
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Config controls the code emitted by a CodeGenerator. The zero value emits
+// a function named formatTime in package main.
+type Config struct {
+	// Package is the name of the package of the emitted source code. When
+	// empty, "main" is used.
+	Package string
+
+	// FuncName is the name of the emitted formatting function. When empty,
+	// "formatTime" is used.
+	FuncName string
+
+	// Header is written verbatim before the package clause, and is
+	// typically a comment that describes how the file was generated.
+	Header string
+
+	// AllowExtra permits the non-standard formatting verbs %1, %2, %3, and
+	// %4 in the spec.
+	AllowExtra bool
+
+	// EmitMain causes a main function to be emitted that prints an example
+	// time formatted by the emitted function.
+	EmitMain bool
+
+	// Reformat used to cause the emitted source code to be formatted by
+	// gofmt.
+	//
+	// Deprecated: The emitted source code is always formatted, because the
+	// emitted functions are built as syntax trees and printed by go/printer.
+	Reformat bool
+
+	// Annotate causes each part of the emitted functions to be preceded by a
+	// comment naming the method of the generator that emitted it, which
+	// helps when debugging the generator.
+	Annotate bool
+
+	// UseAppend causes the emitted function to append to its byte slice
+	// argument rather than copy into it. Like strconv.AppendInt and
+	// time.AppendFormat, the function appends after the existing contents
+	// of the byte slice.
+	UseAppend bool
+
+	// Truncate causes a function emitted with UseAppend to discard the
+	// existing contents of its byte slice argument before appending to it.
+	Truncate bool
+
+	// UseWriter causes the emitted function to write to a *bufio.Writer
+	// rather than to a byte slice, and to return the error from writing.
+	// The function appends directly to the available buffer of the writer,
+	// so it needs no scratch byte slice. The emitted code requires Go 1.18
+	// or later.
+	UseWriter bool
+
+	// ParseFuncName, when not empty, is the name of a function to emit that
+	// parses a byte slice formatted according to the spec.
+	ParseFuncName string
+
+	// Layout causes the spec to be interpreted as a Go reference layout, as
+	// used by time.Format, rather than as a strftime spec.
+	Layout bool
+
+	// EmitTest causes the source code of a test file to also be generated,
+	// and made available by WriteTestTo. It contains a fuzz target for each
+	// emitted function that compares its output with time.Format for Go
+	// reference layouts, or with a reference strftime interpreter.
+	EmitTest bool
+
+	// EmitBench causes the source code of a test file to also be generated,
+	// and made available by WriteTestTo. For each emitted function, it
+	// contains a benchmark of the function, and a benchmark of the standard
+	// library formatting the same time with the equivalent Go reference
+	// layout. The latter is skipped when the spec has no equivalent layout.
+	EmitBench bool
+
+	// ZoneStyle selects what the emitted function writes for %Z. The zero
+	// value writes the zone abbreviation. It does not affect the MST element
+	// of Go reference layouts, which is always written like time.Format
+	// writes it.
+	ZoneStyle ZoneStyle
+
+	// Locale holds the names of weekdays, months, and the AM/PM indicator
+	// that the emitted function writes and parses. When nil, English is
+	// used. Go reference layouts are always English.
+	Locale *Locale
+
+	// CacheType, when not empty, is the name of a type to also emit, whose
+	// AppendFormat method formats times like the emitted function, but
+	// reuses the text it formatted for the previous Unix second, so that
+	// only the fractional seconds are formatted again. It cannot be used
+	// with Go reference layouts.
+	CacheType string
+
+	// CacheSync makes the type named by CacheType safe for concurrent use.
+	// Otherwise each goroutine should use its own value of the type.
+	CacheSync bool
+
+	// FormatterType, when not empty, is the name of a type to also emit, an
+	// empty struct whose methods format values with the emitted function:
+	// AppendFormat, Format, MaxLen, and Spec. Its AppendFormat, Format, and
+	// Spec methods are those of strftime.Formatter, so either may be used
+	// through an interface of those methods. It cannot be used with
+	// UseWriter.
+	FormatterType string
+
+	// DigitPairs causes the emitted function to write zero padded numbers
+	// two digits at a time, from a 200 byte table of the pairs "00" through
+	// "99", rather than one digit at a time. It halves the number of
+	// divisions, at the cost of a larger table. Which is faster depends on
+	// the spec and the machine, so it is worth benchmarking both.
+	DigitPairs bool
+
+	// Duration causes the emitted function to format a time.Duration
+	// rather than a time.Time, using the duration verbs: %d, %H, %M, and
+	// %S for days, hours, minutes, and seconds; %N, %3, and %4 for
+	// fractional seconds; and %+ for the sign. When FuncName is empty,
+	// "formatDuration" is used. It cannot be used with Layout,
+	// ParseFuncName, or CacheType.
+	Duration bool
+}
+
+// ZoneStyle selects what the emitted function writes for the %Z formatting
+// verb.
+type ZoneStyle int
+
+const (
+	// ZoneAbbreviation writes the zone abbreviation, as returned by
+	// time.Time.Zone, which is empty for some locations.
+	ZoneAbbreviation ZoneStyle = iota
+
+	// ZoneAbbreviationOrOffset writes the zone abbreviation, or the zone
+	// offset like %z does when the abbreviation is empty.
+	ZoneAbbreviationOrOffset
+
+	// ZoneLocation writes the name of the location, as returned by
+	// time.Location.String, such as "America/New_York".
+	ZoneLocation
+)
+
+// returnValues holds the initialization of symbols returned by gensym, and
+// the symbols it declares, which are "_" for the values that are not used.
+type returnValues struct {
+	init   ast.Expr
+	values []string
+}
+
+// CodeGenerator holds the Go source code generated for a time format spec.
+type CodeGenerator struct {
+	libraries      map[string]struct{}
+	valuesFromInit map[string]*returnValues
+	initFromSymbol map[string]string
+	orderedSymbols []string
+
+	// buf stores all of the formatting operations needed to be performed in the
+	// order in which they are required to run. We have to build this out of
+	// band with emitting the final output because before we have to identify
+	// which imports to include at the top of the output before we emit these
+	// formatting operations.
+	buf []byte
+
+	// directives stores the sequence of literal text and formatting verbs
+	// scanned from the spec, shared by the format and parse generators.
+	directives []directive
+
+	// formatSource and parseSource store the operations built by scan and
+	// scanParse, until they are wrapped in their respective functions.
+	formatSource code
+	parseSource  code
+
+	// testBuf stores the generated test source code when emitTest or
+	// emitBench is true.
+	testBuf []byte
+
+	header                                               string
+	spec                                                 string
+	packageName                                          string
+	functionName                                         string
+	gensymCounter                                        int
+	offset                                               int // While >= 0, use this for offset; when -1 use runtime offset
+	maxLength                                            int
+	variableWidth                                        bool // set by useRuntimeOffset, even when appending
+	tables                                               tables
+	annotate                                             bool
+	allowExtra, emitMain, useAppend, truncate, useWriter bool
+	emitTest, emitBench, layout, duration, digitPairs    bool
+	zoneStyle                                            ZoneStyle
+	locale                                               *Locale
+
+	// dynamicLengths stores the symbols of the values, such as zone names,
+	// whose lengths are only known at runtime. A function that copies sizes
+	// its buffer by adding their lengths to maxLength.
+	dynamicLengths []string
+
+	// cache generates the function that fills the cache of the caching
+	// formatter type, when one is emitted. That generator has cacheFill
+	// set, and records in cutDigits the number of digits of each of the
+	// fractional seconds it omits.
+	cache                *CodeGenerator
+	cacheType            string
+	cacheSync, cacheFill bool
+	cutDigits            []int
+
+	// fastSource writes every byte at an offset known while generating
+	// code, when only the years of the spec, written by writeYearDigits,
+	// vary in width. It is run when all of fastGuards hold, and writes
+	// fastLength bytes. While fixedYears is set, writeYearDigits records
+	// those guards rather than writing wider years.
+	fastSource code
+	fastGuards []ast.Expr
+	fastLength int
+	fixedYears bool
+
+	// formatterType is the name of the formatter type to emit, if any.
+	formatterType string
+
+	// The following are only used when emitting a parse function.
+	parseFunctionName                          string
+	parseTables                                tables
+	parseMonthDay                              bool
+	parseCentury, parseYearInCentury           bool
+	parseYearDay, parse12Hour, parsePM         bool
+	parseEpoch, parseZoneOffset, parseZoneName bool
+	parseZoneLocation                          bool
+}
+
+// NewCodeGenerator returns a CodeGenerator holding the Go source code that
+// formats time.Time values according to spec, using the options from config.
+// A nil config is equivalent to a pointer to the zero value of Config.
+func NewCodeGenerator(spec string, config *Config) (*CodeGenerator, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	if config.FuncName == "" {
+		config.FuncName = "formatTime"
+		if config.Duration {
+			config.FuncName = "formatDuration"
+		}
+	}
+
+	cg, err := newCodeGenerator(spec, config.FuncName, config)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cg.prepare([]*CodeGenerator{cg}, false); err != nil {
+		return nil, err
+	}
+
+	return cg, nil
+}
+
+// Function associates the name of a formatting function with its time format
+// spec.
+type Function struct {
+	Name string
+	Spec string
+
+	// Layout causes Spec to be interpreted as a Go reference layout, like
+	// the Layout field of Config does for every function.
+	Layout bool
+}
+
+// NewManifestCodeGenerator returns a CodeGenerator holding the Go source code
+// for each of the formatting functions, emitted into a single file. The
+// imports of all functions are merged, and the lookup tables they require are
+// declared once at package level rather than inside every function, with
+// names prefixed by "sft" so that they do not collide with the names of the
+// package. The FuncName field of config is ignored, and a manifest cannot
+// emit parse functions, caches, or formatter types.
+func NewManifestCodeGenerator(functions []Function, config *Config) (*CodeGenerator, error) {
+	if len(functions) == 0 {
+		return nil, errors.New("cannot create code generator without functions")
+	}
+	if config == nil {
+		config = &Config{}
+	}
+	switch {
+	case config.ParseFuncName != "":
+		return nil, errors.New("cannot emit parse function for manifests")
+	case config.CacheType != "":
+		return nil, errors.New("cannot use cache with manifests")
+	case config.FormatterType != "":
+		return nil, errors.New("cannot emit formatter type for manifests")
+	}
+	if config.Package == "" {
+		config.Package = "main"
+	}
+
+	file := &CodeGenerator{
+		libraries: make(map[string]struct{}),
+	}
+	generators := make([]*CodeGenerator, len(functions))
+	names := make(map[string]struct{}, len(functions))
+
+	for i, f := range functions {
+		if !token.IsIdentifier(f.Name) {
+			return nil, fmt.Errorf("cannot use %q as function name", f.Name)
+		}
+		if _, ok := names[f.Name]; ok {
+			return nil, fmt.Errorf("cannot use %q as function name more than once", f.Name)
+		}
+		names[f.Name] = struct{}{}
+
+		cg, err := newCodeGenerator(f.Spec, f.Name, &Config{
+			AllowExtra: config.AllowExtra,
+			UseAppend:  config.UseAppend,
+			Truncate:   config.Truncate,
+			UseWriter:  config.UseWriter,
+			Annotate:   config.Annotate,
+			Layout:     config.Layout || f.Layout,
+			Duration:   config.Duration,
+			DigitPairs: config.DigitPairs,
+			ZoneStyle:  config.ZoneStyle,
+			Locale:     config.Locale,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate %s: %w", f.Name, err)
+		}
+		generators[i] = cg
+	}
+
+	file.header = config.Header
+	file.packageName = config.Package
+	file.emitMain = config.EmitMain
+	file.annotate = config.Annotate
+	file.emitTest = config.EmitTest
+	file.emitBench = config.EmitBench
+
+	if err := file.prepare(generators, true); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// newCodeGenerator returns a CodeGenerator for a single function named
+// functionName, after it has built the operations required to format, and
+// optionally parse, according to spec.
+func newCodeGenerator(spec, functionName string, config *Config) (*CodeGenerator, error) {
+	var err error
+
+	if spec == "" {
+		return nil, errors.New("cannot create code generator without time format spec")
+	}
+	if config.Package == "" {
+		config.Package = "main"
+	}
+	cg := &CodeGenerator{
+		valuesFromInit: make(map[string]*returnValues),
+		initFromSymbol: make(map[string]string),
+		libraries:      make(map[string]struct{}),
+		spec:           spec,
+		packageName:    config.Package,
+		functionName:   functionName,
+		header:         config.Header,
+		allowExtra:     config.AllowExtra,
+		emitMain:       config.EmitMain,
+		useAppend:      config.UseAppend || config.UseWriter, // a writer is appended to
+		truncate:       config.Truncate,
+		useWriter:      config.UseWriter,
+		annotate:       config.Annotate,
+		emitTest:       config.EmitTest,
+		emitBench:      config.EmitBench,
+		layout:         config.Layout,
+		duration:       config.Duration,
+		digitPairs:     config.DigitPairs,
+		zoneStyle:      config.ZoneStyle,
+		locale:         config.Locale,
+
+		parseFunctionName: config.ParseFuncName,
+	}
+
+	// The generator keeps its own copy of the locale, which its caller may
+	// change afterwards.
+	if cg.locale == nil {
+		l := english
+		cg.locale = &l
+	} else {
+		l := *cg.locale
+		cg.locale = &l
+		if err = cg.locale.validate(); err != nil {
+			return nil, err
+		}
+	}
+	if config.Layout && !cg.locale.isEnglish() {
+		return nil, errors.New("cannot use locale with Go reference layouts")
+	}
+
+	if cg.useWriter {
+		cg.libraries["bufio"] = struct{}{}
+	}
+
+	if config.Duration {
+		if err = checkDuration(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.Layout {
+		cg.directives = scanLayout(spec)
+	} else if cg.directives, err = scanSpec(spec); err != nil {
+		return nil, err
+	}
+
+	if cg.duration {
+		cg.formatSource, err = cg.scanDuration()
+	} else if cg.formatSource, err = cg.scan(); err == nil {
+		err = cg.scanFixedYears()
+	}
+	if err != nil {
+		return nil, err
+	}
+	// fmt.Fprintf(os.Stderr, "BEGIN:\n%s\nEND\n", cg.formatSource)
+
+	if cg.parseFunctionName != "" {
+		cg.libraries["fmt"] = struct{}{} // for parse errors
+		if cg.parseSource, err = cg.scanParse(); err != nil {
+			return nil, err
+		}
+	}
+
+	if config.FormatterType != "" {
+		if err = checkFormatterType(config); err != nil {
+			return nil, err
+		}
+		cg.formatterType = config.FormatterType
+	}
+
+	if config.CacheType != "" {
+		if cg.cache, err = cg.newCacheGenerator(config.CacheType, config.CacheSync); err != nil {
+			return nil, err
+		}
+	}
+
+	return cg, nil
+}
+
+// scanFixedYears scans the directives again when the function copies, and
+// only the years of the spec vary in width, because they are written in full
+// outside of the range that their width allows. When the second scan, which
+// writes the years with exactly that width, writes every byte at an offset
+// known while generating code, it becomes the fast path of the function, run
+// when every year is within that range.
+func (cg *CodeGenerator) scanFixedYears() error {
+	if cg.useAppend || cg.offset >= 0 || len(cg.dynamicLengths) > 0 {
+		return nil
+	}
+	maxLength, variableWidth := cg.maxLength, cg.variableWidth
+	cg.offset, cg.maxLength, cg.fixedYears = 0, 0, true
+
+	fast, err := cg.scan()
+	if err == nil && cg.offset > 0 && len(cg.fastGuards) > 0 {
+		cg.fastSource, cg.fastLength = fast, cg.offset
+	} else {
+		cg.fastGuards = nil
+	}
+
+	cg.offset, cg.maxLength, cg.variableWidth, cg.fixedYears = -1, maxLength, variableWidth, false
+	return err
+}
+
+// directive is either a run of literal text or a single formatting verb
+// found while scanning a time format spec.
+type directive struct {
+	literal string // literal text, used when verb is 0
+	verb    rune   // formatting verb that followed a percent sign
+	index   int    // byte index of the verb within the spec
+
+	// GNU flags that may appear between the percent sign and the verb.
+	pad      byte // '-' for no padding, '_' for spaces, '0' for zeros, or 0 for the verb's default
+	upcase   bool // '^' flag: use upper case
+	swapcase bool // '#' flag: use the opposite case
+
+	// GNU field width that may appear between the flags and the verb, or 0
+	// for the verb's default width.
+	width int
+
+	// Number of GNU colons that may appear between the field width and the
+	// %z verb, which select the form of the zone offset.
+	colons int
+
+	// Only used by the verbs that result from scanning Go reference layouts.
+	zulu      bool // write 'Z' rather than a zero zone offset
+	colon     bool // separate zone offset fields with colons
+	precision int  // zone offset fields: 1 for hours, 2 adds minutes, 3 adds seconds
+	separator byte // fractional second separator, '.' or ','
+	trim      bool // trim trailing zeros from fractional second
+}
+
+// padding returns the padding flag of the directive, or def when the spec
+// does not specify one.
+func (d directive) padding(def byte) byte {
+	if d.pad != 0 {
+		return d.pad
+	}
+	return def
+}
+
+// fieldWidth returns the width of the directive, or def when the spec does
+// not specify one, or specifies one narrower than def.
+func (d directive) fieldWidth(def int) int {
+	if d.width > def {
+		return d.width
+	}
+	return def
+}
+
+// yearWidth returns the number of digits that the year of a %Y or %G
+// directive is padded to. Like GNU, and unlike fieldWidth, it honours a field
+// width narrower than the 4 digits of the default.
+func (d directive) yearWidth() int {
+	if d.width > 0 {
+		return d.width
+	}
+	return 4
+}
+
+// fieldFill returns the byte used to pad the output of the directive on the
+// left to its field width, or 0 when its output needs no such padding. The
+// numeric verbs with a fixed number of digits, %N, %z, and fractional seconds
+// from Go reference layouts handle their width themselves.
+func (d directive) fieldFill() byte {
+	if d.width == 0 || d.pad == '-' {
+		return 0
+	}
+	switch d.verb {
+	case 'C', 'd', 'e', 'g', 'G', 'H', 'I', 'j', 'k', 'l', 'm', 'M', 'N', 'S', 'U', 'V', 'W', 'y', 'Y', 'z', verbLayoutFraction:
+		return 0
+	}
+	switch d.pad {
+	case '0':
+		return '0'
+	case '_':
+		return ' '
+	}
+	switch d.verb {
+	case 's', 'u', 'w':
+		return '0'
+	}
+	return ' '
+}
+
+// constantText returns the text that the directive writes when it always
+// writes the same text.
+func (d directive) constantText() (string, bool) {
+	if d.verb == 0 {
+		return d.literal, true
+	}
+	if d.fieldFill() != 0 {
+		return "", false
+	}
+	switch d.verb {
+	case '%':
+		return "%", true
+	case 'n':
+		return "\n", true
+	case 't':
+		return "\t", true
+	}
+	return "", false
+}
+
+// mergeConstants returns the directives with every run of directives that
+// always write the same text replaced by a single literal, so that the text
+// is written by a single operation.
+func mergeConstants(directives []directive) []directive {
+	merged := make([]directive, 0, len(directives))
+	for _, d := range directives {
+		text, ok := d.constantText()
+		if !ok {
+			merged = append(merged, d)
+			continue
+		}
+		if n := len(merged); n > 0 && merged[n-1].verb == 0 {
+			merged[n-1].literal += text
+			continue
+		}
+		merged = append(merged, directive{literal: text})
+	}
+	return merged
+}
+
+// isVerbRune returns true when r may be a formatting verb that follows a
+// field width. The percent sign is excluded so that an extra verb may be
+// immediately followed by another verb, as in "%3%1".
+func isVerbRune(r rune) bool {
+	return ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || r == '+'
+}
+
+// scanSpec splits spec into a sequence of directives, so that every code
+// generator walks the verbs in exactly the same way.
+func scanSpec(spec string) ([]directive, error) {
+	var directives []directive
+	var stringConstant []byte
+	var foundPercent bool
+	var d directive
+	var widthIndex, widthDigits int
+
+	for ri, rune := range spec {
+		if foundPercent && widthDigits == 1 && !isVerbRune(rune) && !('0' <= rune && rune <= '9') && !(rune == ':' && strings.HasPrefix(strings.TrimLeft(spec[ri:], ":"), "z")) {
+			// The extra verbs, %1 through %4, look like a single digit
+			// field width that is followed by neither a verb nor the
+			// colons of %z.
+			d.verb, d.index, d.width = int32('0'+d.width), widthIndex, 0
+			directives = append(directives, d)
+			d = directive{}
+			foundPercent = false
+			widthDigits = 0
+		}
+		if !foundPercent {
+			if rune == '%' {
+				foundPercent = true
+				if len(stringConstant) > 0 {
+					directives = append(directives, directive{literal: string(stringConstant)})
+					stringConstant = stringConstant[:0]
+				}
+			} else {
+				appendRune(&stringConstant, rune)
+			}
+			continue
+		}
+		if rune == ':' {
+			d.colons++ // select the form of the zone offset written by %z
+			continue
+		}
+		if d.colons == 0 && '0' <= rune && rune <= '9' && (widthDigits > 0 || rune != '0') {
+			if widthDigits == 0 {
+				widthIndex = ri
+			}
+			d.width = d.width*10 + int(rune-'0')
+			widthDigits++
+			continue
+		}
+		if widthDigits == 0 && d.colons == 0 {
+			switch rune {
+			case '-', '_', '0':
+				d.pad = byte(rune) // when more than one, the final padding flag wins
+				continue
+			case '^':
+				d.upcase = true
+				continue
+			case '#':
+				d.swapcase = true
+				continue
+			}
+		}
+		d.verb, d.index = rune, ri
+		if d.colons > 0 && d.verb != 'z' {
+			return nil, fmt.Errorf("cannot use colons with format verb %q at index %d", d.verb, d.index)
+		}
+		if d.colons > 3 {
+			return nil, fmt.Errorf("cannot use %d colons with format verb %q at index %d", d.colons, d.verb, d.index)
+		}
+		directives = append(directives, d)
+		d = directive{}
+		foundPercent = false
+		widthDigits = 0
+	}
+
+	if foundPercent {
+		if widthDigits != 1 {
+			return nil, errors.New("cannot find closing format verb")
+		}
+		d.verb, d.index, d.width = int32('0'+d.width), widthIndex, 0
+		directives = append(directives, d)
+	}
+	if len(stringConstant) > 0 {
+		directives = append(directives, directive{literal: string(stringConstant)})
+	}
+
+	return directives, nil
+}
+
+// Scan the spec string and build the output for the required operations.
+func (cg *CodeGenerator) scan() (code, error) {
+	var dest code
+
+	for _, d := range mergeConstants(cg.directives) {
+		if d.verb == 0 {
+			dest = join(dest, cg.writeStringConstant(d.literal))
+			continue
+		}
+		// Following GNU, the '#' flag writes names in upper case, but
+		// writes the AM/PM indicator and zone abbreviation in lower case.
+		upper := d.upcase || d.swapcase
+
+		if digits := d.fractionDigits(); cg.cacheFill && digits > 0 {
+			dest = join(dest, cg.writeCut(digits), cg.writeStringConstant(d.fractionPadding()))
+			continue
+		}
+
+		fill := d.fieldFill()
+		if fill != 0 {
+			dest = join(dest, cg.writeFieldStart())
+		}
+
+		var field code
+
+		switch d.verb {
+		case 'a':
+			field = cg.writeWeekdayShort(upper)
+		case 'A':
+			field = cg.writeWeekdayLong(upper)
+		case 'b':
+			field = cg.writeMonthShort(upper)
+		case 'B':
+			field = cg.writeMonthLong(upper)
+		case 'c':
+			field = cg.writeC(d.upcase)
+		case 'C':
+			field = cg.writeCC(d.padding('0'), d.fieldWidth(2))
+		case 'd':
+			field = cg.writeD(d.padding('0'), d.fieldWidth(2))
+		case 'D':
+			field = cg.writeDC()
+		case 'e':
+			field = cg.writeE(d.padding('_'), d.fieldWidth(2))
+		case 'F':
+			field = cg.writeFC()
+		case 'g':
+			field = cg.writeG(d.padding('0'), d.fieldWidth(2))
+		case 'G':
+			field = cg.writeGC(d.padding('0'), d.yearWidth(), d.width > 0)
+		case 'h':
+			field = cg.writeMonthShort(upper)
+		case 'H':
+			field = cg.writeHC(d.padding('0'), d.fieldWidth(2))
+		case 'I':
+			field = cg.writeIC(d.padding('0'), d.fieldWidth(2))
+		case 'j':
+			field = cg.writeJ(d.padding('0'), d.fieldWidth(3))
+		case 'k':
+			field = cg.writeK(d.padding('_'), d.fieldWidth(2))
+		case 'l':
+			field = cg.writeL(d.padding('_'), d.fieldWidth(2))
+		case 'm':
+			field = cg.writeM(d.padding('0'), d.fieldWidth(2))
+		case 'M':
+			field = cg.writeMC(d.padding('0'), d.fieldWidth(2))
+		case 'n':
+			field = cg.writeN()
+		case 'N':
+			field = cg.writeFractionField(d, cg.writeNC)
+		case 'p':
+			if d.swapcase {
+				field = cg.writePC()
+			} else {
+				field = cg.writeP(d.upcase)
+			}
+		case 'P':
+			// Like GNU, %P is always lower case, even with the '^' flag.
+			field = cg.writePC()
+		case 'r':
+			field = cg.writeR()
+		case 'R':
+			field = cg.writeRC()
+		case 's':
+			field = cg.writeS()
+		case 'S':
+			field = cg.writeSC(d.padding('0'), d.fieldWidth(2))
+		case 't':
+			field = cg.writeT()
+		case 'T':
+			field = cg.writeTC()
+		case 'u':
+			field = cg.writeU()
+		case 'U':
+			field = cg.writeUC(d.padding('0'), d.fieldWidth(2))
+		case 'V':
+			field = cg.writeVC(d.padding('0'), d.fieldWidth(2))
+		case 'w':
+			field = cg.writeW()
+		case 'W':
+			field = cg.writeWC(d.padding('0'), d.fieldWidth(2))
+		case 'x':
+			field = cg.writeDC()
+		case 'X':
+			field = cg.writeTC()
+		case 'y':
+			field = cg.writeY(d.padding('0'), d.fieldWidth(2))
+		case 'Y':
+			field = cg.writeYC(d.padding('0'), d.yearWidth(), d.width > 0)
+		case 'z':
+			if d.width > 0 || d.pad == '-' || d.pad == '_' {
+				field = cg.writeZField(d.colons, d.width, d.pad)
+			} else {
+				field = cg.writeZ(d.colons)
+			}
+		case 'Z':
+			switch {
+			case d.swapcase:
+				field = cg.writeZC(caseLower)
+			case d.upcase:
+				field = cg.writeZC(caseUpper)
+			default:
+				field = cg.writeZC(caseDefault)
+			}
+		case '%':
+			field = cg.writePercent()
+		case '+':
+			field = cg.writePlus(d.upcase)
+		case '1':
+			if !cg.allowExtra {
+				return nil, fmt.Errorf("cannot recognize format verb %q at index %d", d.verb, d.index)
+			}
+			field = cg.writeTZ()
+		case '2':
+			if !cg.allowExtra {
+				return nil, fmt.Errorf("cannot recognize format verb %q at index %d", d.verb, d.index)
+			}
+			field = cg.writeLMin()
+		case '3':
+			if !cg.allowExtra {
+				return nil, fmt.Errorf("cannot recognize format verb %q at index %d", d.verb, d.index)
+			}
+			field = cg.writeMilli()
+		case '4':
+			if !cg.allowExtra {
+				return nil, fmt.Errorf("cannot recognize format verb %q at index %d", d.verb, d.index)
+			}
+			field = cg.writeMicro()
+		case verbLayoutZone:
+			field = cg.writeLayoutZone()
+		case verbLayoutOffset:
+			field = cg.writeLayoutOffset(d.zulu, d.colon, d.precision)
+		case verbLayoutFraction:
+			field = cg.writeFraction(d.separator, d.width, d.trim)
+		default:
+			return nil, fmt.Errorf("cannot recognize format verb %q at index %d", d.verb, d.index)
+		}
+
+		if fill != 0 {
+			field = cg.writeFieldEnd(field, d.width, fill, d.verb == 's')
+		}
+		dest = join(dest, field)
+	}
+
+	return dest, nil
+}
+
+// prepare final output
+//
+// source -> dest -> cg.buf
+//
+// The functions of each of the generators are emitted in order into a single
+// file. When hoist is true, the lookup tables required by the functions are
+// declared once at package level rather than inside each function.
+func (cg *CodeGenerator) prepare(generators []*CodeGenerator, hoist bool) error {
+	var functions []byte
+	var hoisted tables
+	var err error
+
+	for _, g := range generators {
+		for p := range g.libraries {
+			cg.libraries[p] = struct{}{}
+		}
+		hoisted.merge(g.tables)
+		hoisted.merge(g.parseTables)
+		if g.cache != nil {
+			hoisted.merge(g.cache.tables)
+		}
+
+		source, err := g.function(hoist)
+		if err != nil {
+			return err
+		}
+		functions = append(functions, source...)
+	}
+
+	dest := make([]byte, 0, len(cg.header)+4096+len(functions))
+
+	appendString(&dest, "package %s\n\n", cg.packageName)
+
+	//
+	// Library imports
+	//
+	if cg.emitMain {
+		cg.libraries["fmt"] = struct{}{} // for main
+		for _, g := range generators {
+			if g.useWriter {
+				cg.libraries["bytes"] = struct{}{} // for main
+			}
+		}
+	}
+	cg.libraries["time"] = struct{}{}
+
+	sortedLibraries := make([]string, 0, len(cg.libraries))
+	for p := range cg.libraries {
+		sortedLibraries = append(sortedLibraries, p)
+	}
+	sort.Strings(sortedLibraries)
+
+	appendString(&dest, "import (\n")
+	for _, p := range sortedLibraries {
+		appendString(&dest, "    \"%s\"\n", p)
+	}
+	appendString(&dest, ")\n\n")
+
+	//
+	// Main and specified function prefix
+	//
+	if cg.emitMain {
+		when := "time.Date(2006, time.January, 2, 3, 4, 5, 123456789, time.UTC)"
+		if generators[0].duration {
+			when = "-(26*time.Hour + 3*time.Minute + 4*time.Second + 123456789)"
+		}
+		appendString(&dest, "func main() {\n    when := %s\n", when)
+		for _, g := range generators {
+			if g.useWriter {
+				appendString(&dest, `    {
+        var bb bytes.Buffer
+        w := bufio.NewWriter(&bb)
+        if err := %s(w, when); err != nil {
+            panic(err)
+        }
+        if err := w.Flush(); err != nil {
+            panic(err)
+        }
+        fmt.Println(bb.String())
+`, g.functionName)
+				if g.parseFunctionName != "" {
+					appendString(&dest, "        fmt.Println(%s(bb.Bytes()))\n", g.parseFunctionName)
+				}
+				appendString(&dest, "    }\n")
+				continue
+			}
+			// A function that appends keeps the existing contents of its
+			// byte slice, so it is given an empty one.
+			buf := "make([]byte, 128)"
+			if g.useAppend {
+				buf = "make([]byte, 0, 128)"
+			}
+			appendString(&dest, "    fmt.Println(string(%s(%s, when)))\n", g.functionName, buf)
+			if g.parseFunctionName != "" {
+				appendString(&dest, "    fmt.Println(%s(%s(%s, when)))\n", g.parseFunctionName, g.functionName, buf)
+			}
+		}
+		appendString(&dest, "}\n\n")
+	}
+
+	if hoist {
+		bb := new(bytes.Buffer)
+		for _, decl := range tableDecls(hoisted, true) {
+			if err := format.Node(bb, token.NewFileSet(), decl); err != nil {
+				return err
+			}
+			bb.WriteByte('\n')
+		}
+		dest = append(dest, bb.Bytes()...)
+	}
+
+	dest = append(dest, functions...)
+
+	// The header is not Go source, so we need to run gofmt first, then
+	// append the result to after the header. The functions are already
+	// formatted, but the declarations around them are not.
+	dest, err = gofmt(dest)
+	if err != nil {
+		return err
+	}
+	if lh := len(cg.header); lh > 0 {
+		header := make([]byte, 0, lh+len(dest))
+		header = append(header, cg.header...)
+		dest = append(header, dest...)
+	}
+	cg.buf = dest
+
+	if cg.emitTest || cg.emitBench {
+		return cg.prepareTest(generators)
+	}
+	return nil
+}
+
+// function returns the source code of the formatting function, followed by
+// the parse function when one was requested. When hoisted is true, the lookup
+// tables are expected to be declared at package level.
+func (cg *CodeGenerator) function(hoisted bool) ([]byte, error) {
+	var params, results []*ast.Field
+	var body code
+
+	parameter := field("t", sel("time.Time"))
+	if cg.duration {
+		parameter = field("d", sel("time.Duration"))
+	}
+
+	if cg.useWriter {
+		params = []*ast.Field{field("w", &ast.StarExpr{X: sel("bufio.Writer")}), parameter}
+		results = []*ast.Field{field("", ident("error"))}
+	} else if len(cg.cutDigits) > 0 {
+		params = []*ast.Field{field("buf", arrayOf(nil, ident("byte"))), parameter}
+		results = []*ast.Field{field("", arrayOf(nil, ident("byte"))), field("", arrayOf(intLit(len(cg.cutDigits)), ident("int")))}
+		body = varDecl(arrayOf(intLit(len(cg.cutDigits)), ident("int")), "cuts")
+	} else {
+		params = []*ast.Field{field("buf", arrayOf(nil, ident("byte"))), parameter}
+		results = []*ast.Field{field("", arrayOf(nil, ident("byte")))}
+	}
+
+	if !hoisted {
+		body = join(body, declareTables(cg.tables))
+	}
+	if names := referenced(join(cg.fastSource, cg.formatSource), "quotient", "remainder"); len(names) > 0 {
+		body = join(body, varDecl(ident("int"), names...))
+	}
+	body = join(body, note(""))
+
+	if cg.useWriter {
+		body = join(body, define("buf", call("w.AvailableBuffer")), note(""))
+	} else if cg.useAppend {
+		if cg.truncate {
+			body = join(body, ifElse(binary(call("len", ident("buf")), token.GTR, intLit(0)),
+				assign(ident("buf"), token.ASSIGN, sliceExpr(ident("buf"), nil, intLit(0))), nil), note(""))
+		}
+	} else if len(cg.dynamicLengths) == 0 {
+		body = join(body, ifElse(binary(call("len", ident("buf")), token.LSS, intLit(cg.maxLength)),
+			assign(ident("buf"), token.ASSIGN, call("make", arrayOf(nil, ident("byte")), intLit(cg.maxLength))), nil), note(""))
+		if cg.offset > 0 {
+			// Every byte is written at an index known now, so a single
+			// bounds check up front proves all of the stores in bounds.
+			body = join(body, assign(ident("_"), token.ASSIGN, indexExpr(ident("buf"), intLit(cg.offset-1))), note(""))
+		}
+	}
+
+	// dynamically generated variable initializations
+	completedInits := make(map[string]struct{})
+	for _, symbol := range cg.orderedSymbols {
+		init, ok := cg.initFromSymbol[symbol]
+		if !ok {
+			return nil, fmt.Errorf("cannot find initialization for %q", symbol)
+		}
+
+		if _, ok := completedInits[init]; ok {
+			continue // this init and its symbols have already been written
+		}
+		completedInits[init] = struct{}{}
+
+		values, ok := cg.valuesFromInit[init]
+		if !ok {
+			return nil, fmt.Errorf("cannot find values for %q, for %q", init, symbol)
+		}
+		body = join(body, assignList(idents(values.values...), token.DEFINE, []ast.Expr{values.init}))
+	}
+	if !cg.useAppend && len(cg.dynamicLengths) > 0 {
+		// The buffer cannot be sized until the values whose lengths are
+		// only known at runtime have been initialized.
+		n := intLit(cg.maxLength)
+		for _, symbol := range cg.dynamicLengths {
+			n = binary(n, token.ADD, call("len", ident(symbol)))
+		}
+		body = join(body, note(""), ifInit(define("n", n), binary(call("len", ident("buf")), token.LSS, ident("n")),
+			assign(ident("buf"), token.ASSIGN, call("make", arrayOf(nil, ident("byte")), ident("n"))), nil))
+	}
+	body = join(body, note(""))
+
+	if cg.fastSource != nil {
+		// Every byte of the fast path is written at an index known now,
+		// so a single bounds check up front proves all of its stores in
+		// bounds.
+		body = join(body, ifElse(and(cg.fastGuards...), join(
+			assign(ident("_"), token.ASSIGN, indexExpr(ident("buf"), intLit(cg.fastLength-1))),
+			cg.fastSource,
+			returnStmt(sliceExpr(ident("buf"), nil, intLit(cg.fastLength)))), nil), note(""))
+	}
+
+	// Emit all of the operations in their required sequence.
+	body = join(body, cg.formatSource, note(""))
+
+	if !cg.useAppend {
+		if cg.offset >= 0 {
+			// Scanner was able to track offset because everything was fixed
+			// width output.
+			body = join(body, assign(ident("buf"), token.ASSIGN, sliceExpr(ident("buf"), nil, intLit(cg.offset))))
+		} else {
+			// Because one or more formatting verbs were not fixed width output,
+			// scanner was not able to track offset at runtime, and had to emit
+			// code to track it at runtime.
+			body = join(body, assign(ident("buf"), token.ASSIGN, sliceExpr(ident("buf"), nil, ident("offset"))))
+		}
+	}
+	if cg.useWriter {
+		// When the formatted time fits in the available buffer, it is
+		// already in place, and writing it only advances the writer.
+		body = join(body, assignList(idents("_", "err"), token.DEFINE, []ast.Expr{call("w.Write", ident("buf"))}), returnStmt(ident("err")))
+	} else if len(cg.cutDigits) > 0 {
+		body = join(body, returnStmt(ident("buf"), ident("cuts")))
+	} else {
+		body = join(body, returnStmt(ident("buf")))
+	}
+
+	// Symbols that no operation uses, and the initializations that only
+	// they need, are removed, so that the function compiles.
+	body = eliminateDeadSymbols(body)
+	if err := checkSymbols(body); err != nil {
+		return nil, err
+	}
+	if hoisted {
+		hoistTables(body, cg.tables)
+	}
+
+	source, err := printFunction(funcDecl(cg.functionName, params, results, body), cg.annotate)
+	if err != nil {
+		return nil, err
+	}
+
+	var dest []byte
+	if !cg.cacheFill {
+		dest = cg.lengthConstants()
+	}
+	dest = append(dest, source...)
+
+	if cg.parseFunctionName != "" {
+		source, err := cg.prepareParse(cg.parseSource, hoisted)
+		if err != nil {
+			return nil, err
+		}
+		dest = append(dest, source...)
+	}
+
+	if cg.cache != nil {
+		source, err := cg.cache.cacheSource(cg.functionName, hoisted)
+		if err != nil {
+			return nil, err
+		}
+		dest = append(dest, source...)
+	}
+
+	if cg.formatterType != "" {
+		dest = append(dest, cg.formatterSource()...)
+	}
+
+	return dest, nil
+}
+
+// lengthConstants returns the declarations of the constants that hold the
+// maximum length of the text formatted by the emitted function, and its
+// length when the spec is fixed width. There is no maximum length when the
+// text includes zone names.
+func (cg *CodeGenerator) lengthConstants() []byte {
+	var dest []byte
+	if cg.MaxLength() < 0 {
+		return dest
+	}
+	appendString(&dest, "// %sMaxLen is the maximum length of the text formatted by %s.\n", cg.functionName, cg.functionName)
+	appendString(&dest, "const %sMaxLen = %d\n\n", cg.functionName, cg.maxLength)
+	if cg.IsFixedWidth() {
+		appendString(&dest, "// %sFixedLen is the length of every text formatted by %s.\n", cg.functionName, cg.functionName)
+		appendString(&dest, "const %sFixedLen = %d\n\n", cg.functionName, cg.maxLength)
+	}
+	return dest
+}
+
+// MaxLength returns the maximum length of the text formatted by the emitted
+// function, which is also emitted as a constant named after the function,
+// with the suffix MaxLen. It returns -1 when the text includes zone names,
+// which have no maximum length, and 0 for a CodeGenerator returned by
+// NewManifestCodeGenerator, which emits several functions.
+func (cg *CodeGenerator) MaxLength() int {
+	if len(cg.dynamicLengths) > 0 {
+		return -1
+	}
+	return cg.maxLength
+}
+
+// IsFixedWidth returns true when every text formatted by the emitted function
+// is MaxLength bytes long, which is then also emitted as a constant named
+// after the function, with the suffix FixedLen. Verbs whose output length
+// varies with the formatted value, or is only known at runtime, such as %Z,
+// make a spec variable width.
+func (cg *CodeGenerator) IsFixedWidth() bool {
+	return cg.maxLength > 0 && !cg.variableWidth
+}
+
+// Bytes returns the generated source code.
+func (cg *CodeGenerator) Bytes() []byte {
+	return cg.buf
+}
+
+// String returns the generated source code.
+func (cg *CodeGenerator) String() string {
+	return string(cg.buf)
+}
+
+// WriteTo writes the generated source code to iow.
+func (cg *CodeGenerator) WriteTo(iow io.Writer) (int64, error) {
+	n, err := iow.Write(cg.buf)
+	return int64(n), err
+}
+
+// WriteTestTo writes the generated test source code to iow. It returns an
+// error when the CodeGenerator was not created with Config.EmitTest or
+// Config.EmitBench.
+func (cg *CodeGenerator) WriteTestTo(iow io.Writer) (int64, error) {
+	if cg.testBuf == nil {
+		return 0, errors.New("cannot write test without EmitTest or EmitBench option")
+	}
+	n, err := iow.Write(cg.testBuf)
+	return int64(n), err
+}
+
+// gensym returns the symbol of the value at position x, from 1 through y, of
+// the y values of init. Symbols of the same init share its initialization.
+func (cg *CodeGenerator) gensym(x, y int, init ast.Expr) string {
+	x-- // convert x from 1..y to 0..(y-1)
+	var symbol string
+
+	key := types.ExprString(init)
+
+	if values, ok := cg.valuesFromInit[key]; ok {
+		if got, want := len(values.values), y; got != want {
+			// TODO panic should return error
+			panic(fmt.Errorf("found %d return values; expected %d", got, want))
+		}
+		symbol = values.values[x]
+		if symbol == "_" {
+			symbol = cg.symbol()
+			values.values[x] = symbol
+			cg.initFromSymbol[symbol] = key
+		}
+		return symbol
+	}
+
+	symbol = cg.symbol()
+	values := make([]string, y)
+	for i := 0; i < y; i++ {
+		if i == x {
+			values[i] = symbol
+		} else {
+			values[i] = "_"
+		}
+	}
+
+	cg.initFromSymbol[symbol] = key
+	cg.valuesFromInit[key] = &returnValues{init: init, values: values}
+	return symbol
+}
+
+func (cg *CodeGenerator) symbol() string {
+	// Meant to be called by gensym method, but could be called from
+	// elsewhere.
+	symbol := fmt.Sprintf("gs%d", cg.gensymCounter)
+	cg.orderedSymbols = append(cg.orderedSymbols, symbol)
+	cg.gensymCounter++
+	return symbol
+}
+
+// writeDigits writes value as a decimal number of width digits, using pad to
+// select how leading zeros are written: '0' writes them as zeros, '_' writes
+// them as spaces, and '-' omits them.
+func (cg *CodeGenerator) writeDigits(value string, width int, pad byte) code {
+	switch pad {
+	case '_':
+		if width == 2 {
+			return cg.write2DigitsSpace(value)
+		}
+		return join(cg.writeDigitsZero(value, width), cg.padLeadingZeros(width))
+	case '-':
+		if width == 2 {
+			return cg.write2DigitsMin(value)
+		}
+		off := cg.useRuntimeOffset()
+		return join(off, cg.writeDigitsZero(value, width), cg.trimLeadingZeros(width))
+	default:
+		return cg.writeDigitsZero(value, width)
+	}
+}
+
+// writeYearDigits writes value like writeDigits when sign is not negative
+// and value has no more than width digits. Otherwise it writes a minus sign
+// when sign is negative, followed by as many digits as the magnitude of value
+// requires. Like GNU, padding spaces are written before the sign and padding
+// zeros after it. The sign counts toward width when signWidth is true, and
+// otherwise, like time.Format writes years outside of the range 0 through
+// 9999, it is followed by width digits.
+func (cg *CodeGenerator) writeYearDigits(value, sign string, width int, pad byte, signWidth bool) code {
+	condition := binary(ident(sign), token.GEQ, intLit(0))
+	if width < 19 {
+		condition = and(condition, binary(ident(value), token.LSS, intLit(pow10(width))))
+	}
+
+	if cg.fixedYears && pad != '-' {
+		// A year written more than once is guarded once.
+		var guarded bool
+		for _, guard := range cg.fastGuards {
+			guarded = guarded || types.ExprString(guard) == types.ExprString(condition)
+		}
+		if !guarded {
+			cg.fastGuards = append(cg.fastGuards, condition)
+		}
+		return cg.writeDigits(value, width, pad)
+	}
+
+	cg.libraries["strconv"] = struct{}{}
+
+	off := cg.useRuntimeOffset()
+
+	narrow := cg.writeDigits(value, width, pad)
+
+	// A wide value has at most 19 digits and a sign, and is also padded to
+	// width digits.
+	if wide := width + 1; wide > 20 {
+		cg.maxLength += wide - width
+	} else {
+		cg.maxLength += 20 - width
+	}
+
+	// pos returns the expression for the current position within buf.
+	pos := func() ast.Expr {
+		if cg.useAppend {
+			return lenOf("buf")
+		}
+		return ident("offset")
+	}
+
+	// Spaces pad the field from before the sign, and zeros pad the digits
+	// from after it.
+	var spaceStart, zeroStart code
+	switch pad {
+	case '_':
+		spaceStart = define("start", pos())
+	case '0':
+		zeroStart = define("start", pos())
+	}
+
+	if sign == value {
+		sign = "v"
+	}
+
+	var foo code
+	if cg.useAppend {
+		foo = join(note("writeYearDigits append"), define("v", ident(value)), spaceStart,
+			ifElse(binary(ident(sign), token.LSS, intLit(0)), join(cg.writeByte('-'), assign(ident("v"), token.ASSIGN, unary(token.SUB, ident("v")))), nil),
+			zeroStart,
+			assign(ident("buf"), token.ASSIGN, call("strconv.AppendInt", ident("buf"), call("int64", ident("v")), intLit(10))))
+	} else {
+		foo = join(note("writeYearDigits runtime offset"), define("v", ident(value)), spaceStart,
+			ifElse(binary(ident(sign), token.LSS, intLit(0)), join(cg.writeByte('-'), assign(ident("v"), token.ASSIGN, unary(token.SUB, ident("v")))), nil),
+			zeroStart,
+			assign(ident("offset"), token.ADD_ASSIGN, call("len", call("strconv.AppendInt", sliceExpr(ident("buf"), ident("offset"), ident("offset")), call("int64", ident("v")), intLit(10)))))
+	}
+
+	if pad != '-' {
+		// The number of bytes to pad to from start. A value that is not
+		// negative is only written here when it has more than width
+		// digits, so it is never padded.
+		fill, fieldWidth := byte('0'), width
+		if signWidth {
+			fieldWidth--
+		}
+		if pad == '_' {
+			fill = ' '
+			fieldWidth++
+		}
+		var grow code
+		if cg.useAppend {
+			grow = appendAll(call("make", arrayOf(nil, ident("byte")), binary(intLit(fieldWidth), token.SUB, ident("n"))))
+		} else {
+			grow = assign(ident("offset"), token.ASSIGN, plus("start", fieldWidth))
+		}
+		foo = join(foo, ifInit(define("n", binary(pos(), token.SUB, ident("start"))), binary(ident("n"), token.LSS, intLit(fieldWidth)),
+			join(grow, padField(fieldWidth, binary(ident("start"), token.ADD, ident("n")), fill)), nil))
+	}
+
+	return join(off, ifElse(condition, narrow, foo))
+}
+
+// padField returns the code that moves the n bytes of a field, written from
+// start to end, to the end of the field of width bytes, and fills the bytes
+// before them with fill.
+func padField(width int, end ast.Expr, fill byte) code {
+	return join(
+		exprStmt(call("copy", sliceExpr(ident("buf"), binary(plus("start", width), token.SUB, ident("n")), nil), sliceExpr(ident("buf"), ident("start"), end))),
+		forStmt(define("i", ident("start")), binary(ident("i"), token.LSS, binary(plus("start", width), token.SUB, ident("n"))), incDec(ident("i"), token.INC),
+			assign(bufAt(ident("i")), token.ASSIGN, charLit(fill))))
+}
+
+// writeDigitsZero writes value as a zero padded decimal number of width
+// digits.
+func (cg *CodeGenerator) writeDigitsZero(value string, width int) code {
+	switch width {
+	case 2:
+		return cg.write2DigitsZero(value)
+	case 3:
+		return cg.write3DigitsZero(value)
+	case 4:
+		return cg.write4DigitsZero(value)
+	case 6:
+		return cg.write6DigitsZero(value)
+	case 9:
+		return cg.write9DigitsZero(value)
+	default:
+		return cg.writeNDigitsZero(value, width)
+	}
+}
+
+// writeNDigitsZero writes value as a zero padded decimal number of width
+// digits, for the widths that do not have a specialized method.
+func (cg *CodeGenerator) writeNDigitsZero(value string, width int) code {
+	if cg.digitPairs {
+		return cg.writeDigitPairs(value, width)
+	}
+
+	cg.tables.digits = true
+	cg.maxLength += width
+
+	divisor := pow10(width - 1)
+
+	foo := join(note("writeNDigitsZero %s", cg.mode()), assign(ident("remainder"), token.ASSIGN, ident(value)))
+
+	for i := 0; i < width; i++ {
+		foo = join(foo,
+			assign(ident("quotient"), token.ASSIGN, binary(ident("remainder"), token.QUO, intLit(divisor))),
+			assign(ident("remainder"), token.REM_ASSIGN, intLit(divisor)),
+			cg.put(i, digit(ident("quotient"))))
+		divisor /= 10
+	}
+
+	return join(foo, cg.advance(width))
+}
+
+// writeDigitPairs writes value as a zero padded decimal number of width
+// digits, two digits per lookup in the table of digit pairs. When width is
+// odd, the leading digit is the second byte of its pair.
+func (cg *CodeGenerator) writeDigitPairs(value string, width int) code {
+	cg.tables.digitPairs = true
+	cg.maxLength += width
+
+	foo := note("writeDigitPairs %s", cg.mode())
+
+	var written int
+	write := func(index ast.Expr) {
+		foo = join(foo, cg.put(written, indexExpr(ident("digitPairs"), index)))
+		written++
+	}
+
+	// The leading group of digits is divided from value, and each following
+	// one from what remains of it. The final group is what remains, and
+	// needs no division.
+	dividend := value
+	for remaining := width; remaining > 0; {
+		pair := remaining%2 == 0
+		if pair {
+			remaining -= 2
+		} else {
+			remaining--
+		}
+
+		operand := dividend
+		if remaining > 0 {
+			divisor := pow10(remaining)
+			if dividend == value {
+				foo = join(foo,
+					assign(ident("quotient"), token.ASSIGN, binary(ident(value), token.QUO, intLit(divisor))),
+					assign(ident("remainder"), token.ASSIGN, binary(ident(value), token.REM, intLit(divisor))))
+			} else {
+				foo = join(foo,
+					assign(ident("quotient"), token.ASSIGN, binary(ident("remainder"), token.QUO, intLit(divisor))),
+					assign(ident("remainder"), token.REM_ASSIGN, intLit(divisor)))
+			}
+			operand = "quotient"
+			dividend = "remainder"
+		}
+
+		if pair {
+			write(binary(intLit(2), token.MUL, ident(operand)))
+		}
+		write(binary(binary(intLit(2), token.MUL, ident(operand)), token.ADD, intLit(1)))
+	}
+
+	return join(foo, cg.advance(width))
+}
+
+// divideDigits returns the code that writes value as a zero padded decimal
+// number of width digits, dividing it by decreasing powers of ten. When space
+// is true, a leading zero is written as a space instead.
+func (cg *CodeGenerator) divideDigits(value string, width int, space bool) code {
+	divisor := pow10(width - 1)
+	foo := join(
+		assign(ident("quotient"), token.ASSIGN, binary(ident(value), token.QUO, intLit(divisor))),
+		assign(ident("remainder"), token.ASSIGN, binary(ident(value), token.REM, intLit(divisor))))
+
+	var lead ast.Expr = ident("quotient")
+	if space {
+		lead = binary(intLit(10), token.ADD, ident("quotient"))
+	}
+	foo = join(foo, cg.put(0, digit(lead)))
+
+	for i := 1; i < width-1; i++ {
+		divisor /= 10
+		foo = join(foo,
+			assign(ident("quotient"), token.ASSIGN, binary(ident("remainder"), token.QUO, intLit(divisor))),
+			assign(ident("remainder"), token.REM_ASSIGN, intLit(divisor)),
+			cg.put(i, digit(ident("quotient"))))
+	}
+
+	return join(foo, cg.put(width-1, digit(ident("remainder"))), cg.advance(width))
+}
+
+// mode returns how the writers store bytes, which their notes include:
+// "append", "codegen offset" while offsets are known when generating code,
+// or "runtime offset".
+func (cg *CodeGenerator) mode() string {
+	switch {
+	case cg.useAppend:
+		return "append"
+	case cg.offset >= 0:
+		return "codegen offset"
+	}
+	return "runtime offset"
+}
+
+// at returns the index of buf that is delta bytes after the current offset,
+// which is known when generating code unless runtime offsets are used.
+func (cg *CodeGenerator) at(delta int) ast.Expr {
+	if cg.offset >= 0 {
+		return intLit(cg.offset + delta)
+	}
+	return plus("offset", delta)
+}
+
+// put returns the statement that appends the byte x, or unless appending,
+// that stores it delta bytes after the current offset, which advance then
+// moves past the stored bytes.
+func (cg *CodeGenerator) put(delta int, x ast.Expr) code {
+	if cg.useAppend {
+		return appendBytes(x)
+	}
+	return assign(bufAt(cg.at(delta)), token.ASSIGN, x)
+}
+
+// advance moves the current offset past the n bytes stored by put.
+func (cg *CodeGenerator) advance(n int) code {
+	switch {
+	case cg.useAppend:
+		return nil
+	case cg.offset >= 0:
+		cg.offset += n
+		return nil
+	}
+	return assign(ident("offset"), token.ADD_ASSIGN, intLit(n))
+}
+
+// bufAt returns the byte of buf at index i.
+func bufAt(i ast.Expr) ast.Expr {
+	return indexExpr(ident("buf"), i)
+}
+
+// digit returns the byte of the decimal digit x from the table of digits.
+func digit(x ast.Expr) ast.Expr {
+	return indexExpr(ident("digits"), x)
+}
+
+// lenOf returns the length of the variable name.
+func lenOf(name string) ast.Expr {
+	return call("len", ident(name))
+}
+
+// appendBytes returns the statement that appends the bytes xs to buf.
+func appendBytes(xs ...ast.Expr) code {
+	return assign(ident("buf"), token.ASSIGN, call("append", append([]ast.Expr{ident("buf")}, xs...)...))
+}
+
+// appendAll returns the statement that appends all of the bytes of s to buf.
+func appendAll(s ast.Expr) code {
+	return assign(ident("buf"), token.ASSIGN, spread("append", ident("buf"), s))
+}
+
+// useRuntimeOffset returns the declaration of the runtime offset, which
+// starts where the offset tracked while generating code ended, and switches
+// to runtime offsets. Once switched, or when appending, it returns nothing.
+// Every writer whose output varies in length calls it, even when appending,
+// so that it also records that the output is not fixed width.
+func (cg *CodeGenerator) useRuntimeOffset() code {
+	cg.variableWidth = true
+	if cg.useAppend || cg.offset < 0 {
+		return nil
+	}
+	c := join(note("following formatting verb has variable length"), define("offset", intLit(cg.offset)))
+	cg.offset = -1 // must use dynamic offsets
+	return c
+}
+
+// writeFieldStart begins a field whose output is padded on the left to a
+// field width by writeFieldEnd. Because the length of the output varies, it
+// requires either append or runtime offset mode.
+func (cg *CodeGenerator) writeFieldStart() code {
+	return cg.useRuntimeOffset()
+}
+
+// writeFieldEnd returns the field that pads the output written by body on the
+// left with fill until it is width bytes long. When signed is true, the output
+// is a number, and like GNU, zeros are written after its minus sign.
+func (cg *CodeGenerator) writeFieldEnd(body code, width int, fill byte, signed bool) code {
+	cg.maxLength += width
+
+	var sign code
+	if signed && fill == '0' {
+		first := func() ast.Expr {
+			return bufAt(binary(plus("start", width), token.SUB, ident("n")))
+		}
+		sign = ifElse(binary(first(), token.EQL, charLit('-')),
+			assignList([]ast.Expr{bufAt(ident("start")), first()}, token.ASSIGN, []ast.Expr{charLit('-'), charLit('0')}), nil)
+	}
+
+	if cg.useAppend {
+		return join(note("writeFieldEnd append"), block(define("start", lenOf("buf")), body,
+			ifInit(define("n", binary(lenOf("buf"), token.SUB, ident("start"))), binary(ident("n"), token.LSS, intLit(width)), join(
+				forStmt(define("i", ident("n")), binary(ident("i"), token.LSS, intLit(width)), incDec(ident("i"), token.INC), appendBytes(charLit(fill))),
+				padField(width, binary(ident("start"), token.ADD, ident("n")), fill),
+				sign), nil)))
+	}
+
+	return join(note("writeFieldEnd runtime offset"), block(define("start", ident("offset")), body,
+		ifInit(define("n", binary(ident("offset"), token.SUB, ident("start"))), binary(ident("n"), token.LSS, intLit(width)), join(
+			padField(width, ident("offset"), fill),
+			sign,
+			assign(ident("offset"), token.ASSIGN, plus("start", width))), nil)))
+}
+
+// padLeadingZeros replaces all but the final of the leading zeros of the
+// width digits most recently written with spaces.
+func (cg *CodeGenerator) padLeadingZeros(width int) code {
+	// spaces replaces the zeros from index from, but not at index to.
+	spaces := func(from, to ast.Expr) code {
+		return forStmt(define("i", from), and(binary(ident("i"), token.LSS, to), binary(bufAt(ident("i")), token.EQL, charLit('0'))), incDec(ident("i"), token.INC),
+			assign(bufAt(ident("i")), token.ASSIGN, charLit(' ')))
+	}
+
+	if cg.useAppend {
+		return join(note("padLeadingZeros append"), spaces(binary(lenOf("buf"), token.SUB, intLit(width)), binary(lenOf("buf"), token.SUB, intLit(1))))
+	}
+
+	if cg.offset >= 0 {
+		return join(note("padLeadingZeros codegen offset"), spaces(intLit(cg.offset-width), intLit(cg.offset-1)))
+	}
+
+	return join(note("padLeadingZeros runtime offset"), spaces(plus("offset", -width), plus("offset", -1)))
+}
+
+// trimLeadingZeros removes all but the final of the leading zeros of the
+// width digits most recently written. It requires either append or runtime
+// offset mode, because the number of bytes it removes varies.
+func (cg *CodeGenerator) trimLeadingZeros(width int) code {
+	// trim removes the zeros from the digits written before the position
+	// returned by pos, and then runs remove.
+	trim := func(pos func() ast.Expr, remove code) code {
+		return block(
+			define("start", binary(pos(), token.SUB, intLit(width))),
+			define("end", ident("start")),
+			forStmt(nil, and(binary(ident("end"), token.LSS, binary(pos(), token.SUB, intLit(1))), binary(bufAt(ident("end")), token.EQL, charLit('0'))), nil,
+				incDec(ident("end"), token.INC)),
+			remove)
+	}
+
+	if cg.useAppend {
+		return join(note("trimLeadingZeros append"), trim(func() ast.Expr { return lenOf("buf") },
+			assign(ident("buf"), token.ASSIGN, spread("append", sliceExpr(ident("buf"), nil, ident("start")), sliceExpr(ident("buf"), ident("end"), nil)))))
+	}
+
+	return join(note("trimLeadingZeros runtime offset"), trim(func() ast.Expr { return ident("offset") },
+		assign(ident("offset"), token.ASSIGN, binary(ident("start"), token.ADD,
+			call("copy", sliceExpr(ident("buf"), ident("start"), nil), sliceExpr(ident("buf"), ident("end"), ident("offset")))))))
+}
+
+// padTrailingZeros replaces all but the first of the trailing zeros of the
+// width digits most recently written with spaces.
+func (cg *CodeGenerator) padTrailingZeros(width int) code {
+	// spaces replaces the zeros from index from, but not at index to.
+	spaces := func(from, to ast.Expr) code {
+		return forStmt(define("i", from), and(binary(ident("i"), token.GTR, to), binary(bufAt(ident("i")), token.EQL, charLit('0'))), incDec(ident("i"), token.DEC),
+			assign(bufAt(ident("i")), token.ASSIGN, charLit(' ')))
+	}
+
+	if cg.useAppend {
+		return join(note("padTrailingZeros append"), spaces(binary(lenOf("buf"), token.SUB, intLit(1)), binary(lenOf("buf"), token.SUB, intLit(width))))
+	}
+
+	if cg.offset >= 0 {
+		return join(note("padTrailingZeros codegen offset"), spaces(intLit(cg.offset-1), intLit(cg.offset-width)))
+	}
+
+	return join(note("padTrailingZeros runtime offset"), spaces(plus("offset", -1), plus("offset", -width)))
+}
+
+// trimTrailingZeros removes all but the first of the trailing zeros of the
+// width digits most recently written. It requires either append or runtime
+// offset mode, because the number of bytes it removes varies.
+func (cg *CodeGenerator) trimTrailingZeros(width int) code {
+	// trim removes the zeros before the position returned by pos, but not
+	// the first digit, and runs remove for each of them.
+	trim := func(pos func() ast.Expr, remove code) code {
+		return block(
+			define("start", binary(pos(), token.SUB, intLit(width-1))),
+			forStmt(nil, and(binary(pos(), token.GTR, ident("start")), binary(bufAt(binary(pos(), token.SUB, intLit(1))), token.EQL, charLit('0'))), nil,
+				remove))
+	}
+
+	if cg.useAppend {
+		return join(note("trimTrailingZeros append"), trim(func() ast.Expr { return lenOf("buf") },
+			assign(ident("buf"), token.ASSIGN, sliceExpr(ident("buf"), nil, binary(lenOf("buf"), token.SUB, intLit(1))))))
+	}
+
+	return join(note("trimTrailingZeros runtime offset"), trim(func() ast.Expr { return ident("offset") },
+		incDec(ident("offset"), token.DEC)))
+}
+
+func (cg *CodeGenerator) write2DigitsMin(value string) code {
+	cg.tables.digits = true
+	cg.maxLength += 2
+
+	off := cg.useRuntimeOffset()
+
+	return join(off, note("write2DigitsMin %s", cg.mode()),
+		assign(ident("quotient"), token.ASSIGN, binary(ident(value), token.QUO, intLit(10))),
+		assign(ident("remainder"), token.ASSIGN, binary(ident(value), token.REM, intLit(10))),
+		ifElse(binary(ident("quotient"), token.GTR, intLit(0)), cg.writeNext(digit(ident("quotient"))), nil),
+		cg.writeNext(digit(ident("remainder"))))
+}
+
+func (cg *CodeGenerator) write2DigitsSpace(value string) code {
+	cg.tables.digits = true
+	cg.maxLength += 2
+	return join(note("write2DigitsSpace %s", cg.mode()), cg.divideDigits(value, 2, true))
+}
+
+func (cg *CodeGenerator) write2DigitsZero(value string) code {
+	if cg.digitPairs {
+		return cg.writeDigitPairs(value, 2)
+	}
+
+	cg.tables.digits = true
+	cg.maxLength += 2
+	return join(note("write2DigitsZero %s", cg.mode()), cg.divideDigits(value, 2, false))
+}
+
+func (cg *CodeGenerator) write3DigitsZero(value string) code {
+	if cg.digitPairs {
+		return cg.writeDigitPairs(value, 3)
+	}
+
+	cg.tables.digits = true
+	cg.maxLength += 3
+	return join(note("write3DigitsZero %s", cg.mode()), cg.divideDigits(value, 3, false))
+}
+
+func (cg *CodeGenerator) write4DigitsZero(value string) code {
+	if cg.digitPairs {
+		return cg.writeDigitPairs(value, 4)
+	}
+
+	cg.tables.digits = true
+	cg.maxLength += 4
+	return join(note("write4DigitsZero %s", cg.mode()), cg.divideDigits(value, 4, false))
+}
+
+func (cg *CodeGenerator) write6DigitsZero(value string) code {
+	if cg.digitPairs {
+		return cg.writeDigitPairs(value, 6)
+	}
+
+	cg.tables.digits = true
+	cg.maxLength += 6
+	return join(note("write6DigitsZero %s", cg.mode()), cg.divideDigits(value, 6, false))
+}
+
+func (cg *CodeGenerator) write9DigitsZero(value string) code {
+	if cg.digitPairs {
+		return cg.writeDigitPairs(value, 9)
+	}
+
+	cg.tables.digits = true
+	cg.maxLength += 9
+	return join(note("write9DigitsZero %s", cg.mode()), cg.divideDigits(value, 9, false))
+}
+
+// writeName writes the name at index i of the table t, where i is the symbol
+// of an int value. When the names in t differ in length, next is the symbol
+// of the index of the offset where the name ends.
+func (cg *CodeGenerator) writeName(what string, t nameTable, i, next string) code {
+	cg.tables.declare(t)
+
+	if t.width > 0 {
+		cg.maxLength += t.width
+
+		// The end of the name is unused when its bytes are written at
+		// offsets known now, and is then eliminated from the function.
+		indexL := cg.gensym(1, 1, indexExpr(ident(t.indices), ident(i)))
+		indexR := cg.gensym(1, 1, binary(ident(indexL), token.ADD, intLit(t.width)))
+
+		if cg.useAppend {
+			return join(section("%s append", what), appendAll(sliceExpr(ident(t.constant), ident(indexL), ident(indexR))))
+		}
+
+		if cg.offset >= 0 {
+			foo := section("%s codegen offset", what)
+			for j := 0; j < t.width; j++ {
+				foo = join(foo, assign(bufAt(intLit(cg.offset)), token.ASSIGN, indexExpr(ident(t.constant), plus(indexL, j))))
+				cg.offset++
+			}
+			return foo
+		}
+
+		return join(section("%s runtime offset", what), copyAt(sliceExpr(ident(t.constant), ident(indexL), ident(indexR))))
+	}
+
+	cg.maxLength += t.longest
+
+	indexL := cg.gensym(1, 1, indexExpr(ident(t.indices), ident(i)))
+	indexR := cg.gensym(1, 1, indexExpr(ident(t.indices), ident(next)))
+
+	off := cg.useRuntimeOffset()
+
+	if cg.useAppend {
+		return join(section("%s append", what), appendAll(sliceExpr(ident(t.constant), ident(indexL), ident(indexR))))
+	}
+
+	return join(off, section("%s runtime offset", what), copyAt(sliceExpr(ident(t.constant), ident(indexL), ident(indexR))))
+}
+
+// copyAt returns the statement that copies the bytes of s to buf at the
+// runtime offset, and advances it past them.
+func copyAt(s ast.Expr) code {
+	return assign(ident("offset"), token.ADD_ASSIGN, call("copy", sliceExpr(ident("buf"), ident("offset"), nil), s))
+}
+
+// weekdayName writes the name of the weekday from the table t.
+func (cg *CodeGenerator) weekdayName(what string, t nameTable) code {
+	wd := cg.gensym(1, 1, call("t.Weekday"))
+	var next string
+	if t.width == 0 {
+		next = cg.gensym(1, 1, binary(ident(wd), token.ADD, intLit(1)))
+	}
+	return cg.writeName(what, t, wd, next)
+}
+
+// monthName writes the name of the month from the table t.
+func (cg *CodeGenerator) monthName(what string, t nameTable) code {
+	month := cg.gensym(2, 3, call("t.Date"))
+	monthMinusOne := cg.gensym(1, 1, binary(ident(month), token.SUB, intLit(1)))
+	return cg.writeName(what, t, monthMinusOne, month)
+}
+
+func (cg *CodeGenerator) writeWeekdayShort(upper bool) code {
+	return cg.weekdayName("Weekday Short", cg.locale.weekdaysShort(upper))
+}
+
+func (cg *CodeGenerator) writeWeekdayLong(upper bool) code {
+	return cg.weekdayName("Weekday Long", cg.locale.weekdaysLong(upper))
+}
+
+func (cg *CodeGenerator) writeMonthShort(upper bool) code {
+	return cg.monthName("Month Short", cg.locale.monthsShort(upper))
+}
+
+func (cg *CodeGenerator) writeMonthLong(upper bool) code {
+	return cg.monthName("Month Long", cg.locale.monthsLong(upper))
+}
+
+func (cg *CodeGenerator) writeStringConstant(someString string) code {
+	ls := len(someString)
+	if ls == 0 {
+		return nil
+	}
+	cg.maxLength += ls
+
+	if cg.useAppend {
+		return join(note("writeStringConstant append"), appendAll(strLit(someString)))
+	}
+
+	if cg.offset >= 0 {
+		cg.offset += ls
+		if ls == 1 {
+			return join(note("writeStringConstant codegen offset"), assign(bufAt(intLit(cg.offset-1)), token.ASSIGN, charLit(someString[0])))
+		}
+		// According to Go standard library, runtime.memmove optimizes
+		// transfers of byte slices less than 2K characters, and the
+		// compiler turns a copy of a short constant string to a constant
+		// slice of the buffer into a few stores. This does not check the
+		// size of the string constants, because it is unrealistic that
+		// they are going to be longer than 2 KiB.
+		return join(note("writeStringConstant codegen offset"), exprStmt(call("copy", sliceExpr(ident("buf"), intLit(cg.offset-ls), intLit(cg.offset)), strLit(someString))))
+	}
+
+	return join(note("writeStringConstant runtime offset"), copyAt(strLit(someString)))
+}
+
+func (cg *CodeGenerator) writeStringValue(someValue string) code {
+	off := cg.useRuntimeOffset()
+	if cg.useAppend {
+		return join(note("writeStringValue"), appendAll(ident(someValue)))
+	}
+	return join(off, note("writeStringValue runtime offset"), copyAt(ident(someValue)))
+}
+
+func (cg *CodeGenerator) writeC(upper bool) code {
+	foo := section("writeC")
+	foo = join(foo, cg.writeWeekdayShort(upper))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeMonthShort(upper))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeE('_', 2))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeTC())
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeYC('0', 4, false))
+	return foo
+}
+
+func (cg *CodeGenerator) writeCC(pad byte, width int) code {
+	year := cg.gensym(1, 3, call("t.Date"))
+	century := cg.gensym(1, 1, binary(ident(year), token.QUO, intLit(100)))
+	return join(section("writeCC"), cg.writeYearDigits(century, year, width, pad, true))
+}
+
+func (cg *CodeGenerator) writeD(pad byte, width int) code {
+	date := cg.gensym(3, 3, call("t.Date"))
+	return join(section("writeD"), cg.writeDigits(date, width, pad))
+}
+
+func (cg *CodeGenerator) writeDC() code {
+	foo := section("writeDC")
+	foo = join(foo, cg.write2DigitsZero(cg.gensym(1, 1, call("int", ident(cg.gensym(2, 3, call("t.Date")))))))
+	foo = join(foo, cg.writeStringConstant("/"))
+	foo = join(foo, cg.write2DigitsZero(cg.gensym(3, 3, call("t.Date"))))
+	foo = join(foo, cg.writeStringConstant("/"))
+	foo = join(foo, cg.write2DigitsZero(cg.yearInCentury(cg.gensym(1, 3, call("t.Date")))))
+	return foo
+}
+
+func (cg *CodeGenerator) writeE(pad byte, width int) code {
+	date := cg.gensym(3, 3, call("t.Date"))
+	return join(section("writeE"), cg.writeDigits(date, width, pad))
+}
+
+func (cg *CodeGenerator) writeFC() code {
+	year := cg.gensym(1, 3, call("t.Date"))
+	month := cg.gensym(2, 3, call("t.Date"))
+	date := cg.gensym(3, 3, call("t.Date"))
+	monthInt := cg.gensym(1, 1, call("int", ident(month)))
+	foo := section("writeFC")
+	foo = join(foo, cg.writeYearDigits(year, year, 4, '0', false))
+	foo = join(foo, cg.writeStringConstant("-"))
+	foo = join(foo, cg.write2DigitsZero(monthInt))
+	foo = join(foo, cg.writeStringConstant("-"))
+	foo = join(foo, cg.write2DigitsZero(date))
+	return foo
+}
+
+// yearInCentury returns the symbol of the last two digits of year. Like
+// time.Format and GNU, they are the same for negative years as for positive
+// years.
+func (cg *CodeGenerator) yearInCentury(year string) string {
+	remainder := cg.gensym(1, 1, binary(ident(year), token.REM, intLit(100)))
+	mask := cg.gensym(1, 1, binary(ident(remainder), token.SHR, intLit(63))) // -1 when negative, otherwise 0
+	return cg.gensym(1, 1, binary(paren(binary(ident(remainder), token.XOR, ident(mask))), token.SUB, ident(mask)))
+}
+
+func (cg *CodeGenerator) writeG(pad byte, width int) code {
+	year := cg.gensym(1, 2, call("t.ISOWeek"))
+	return join(section("writeG"), cg.writeDigits(cg.yearInCentury(year), width, pad))
+}
+
+func (cg *CodeGenerator) writeGC(pad byte, width int, signWidth bool) code {
+	year := cg.gensym(1, 2, call("t.ISOWeek"))
+	return join(section("writeGC"), cg.writeYearDigits(year, year, width, pad, signWidth))
+}
+
+func (cg *CodeGenerator) writeHC(pad byte, width int) code {
+	hour := cg.gensym(1, 3, call("t.Clock"))
+	return join(section("writeHC"), cg.writeDigits(hour, width, pad))
+}
+
+// hour12 returns the symbol of the hour on a 12-hour clock. Following POSIX,
+// midnight and noon are hour 12 rather than hour 0.
+func (cg *CodeGenerator) hour12() string {
+	hour := cg.gensym(1, 3, call("t.Clock"))
+	return cg.gensym(1, 1, binary(binary(paren(binary(ident(hour), token.ADD, intLit(11))), token.REM, intLit(12)), token.ADD, intLit(1)))
+}
+
+func (cg *CodeGenerator) writeIC(pad byte, width int) code {
+	hour12 := cg.hour12()
+	return join(section("writeIC"), cg.writeDigits(hour12, width, pad))
+}
+
+func (cg *CodeGenerator) writeJ(pad byte, width int) code {
+	yearday := cg.gensym(1, 1, call("t.YearDay"))
+	return join(section("writeJ"), cg.writeDigits(yearday, width, pad))
+}
+
+func (cg *CodeGenerator) writeK(pad byte, width int) code {
+	hour := cg.gensym(1, 3, call("t.Clock"))
+	return join(section("writeK"), cg.writeDigits(hour, width, pad))
+}
+
+func (cg *CodeGenerator) writeL(pad byte, width int) code {
+	hour12 := cg.hour12()
+	return join(section("writeL"), cg.writeDigits(hour12, width, pad))
+}
+
+func (cg *CodeGenerator) writeLMin() code {
+	hour12 := cg.hour12()
+	return join(section("writeLMin"), cg.write2DigitsMin(hour12))
+}
+
+func (cg *CodeGenerator) writeM(pad byte, width int) code {
+	month := cg.gensym(2, 3, call("t.Date"))
+	monthInt := cg.gensym(1, 1, call("int", ident(month)))
+	return join(section("writeM"), cg.writeDigits(monthInt, width, pad))
+}
+
+func (cg *CodeGenerator) writeMC(pad byte, width int) code {
+	minute := cg.gensym(2, 3, call("t.Clock"))
+	return join(section("writeMC"), cg.writeDigits(minute, width, pad))
+}
+
+func (cg *CodeGenerator) writeN() code {
+	return join(section("writeN"), cg.writeStringConstant("\n"))
+}
+
+// writeNC writes the fractional second truncated to width digits.
+func (cg *CodeGenerator) writeNC(width int) code {
+	nanos := cg.gensym(1, 1, call("t.Nanosecond"))
+	if width < 9 {
+		nanos = cg.gensym(1, 1, binary(ident(nanos), token.QUO, intLit(pow10(9-width))))
+	}
+	return join(section("writeNC"), cg.writeDigitsZero(nanos, width))
+}
+
+// writeFractionField writes the fractional second digits of the directive with
+// digits, which writes them truncated to a width. Like GNU, the '-' flag of
+// %N removes their trailing zeros and the '_' flag replaces them with spaces,
+// always leaving the first digit, before the field is padded to its width.
+func (cg *CodeGenerator) writeFractionField(d directive, digits func(width int) code) code {
+	width := d.fractionDigits()
+	if d.verb != 'N' {
+		return digits(width)
+	}
+
+	var field code
+	switch d.pad {
+	case '-':
+		off := cg.useRuntimeOffset()
+		field = join(off, digits(width), cg.trimTrailingZeros(width))
+	case '_':
+		field = join(digits(width), cg.padTrailingZeros(width))
+	default:
+		field = digits(width)
+	}
+	return join(field, cg.writeStringConstant(d.fractionPadding()))
+}
+
+func (cg *CodeGenerator) writeMicro() code {
+	nanos := cg.gensym(1, 1, call("t.Nanosecond"))
+	micros := cg.gensym(1, 1, binary(ident(nanos), token.QUO, intLit(1000)))
+	return join(section("writeMicro"), cg.write6DigitsZero(micros))
+}
+
+func (cg *CodeGenerator) writeMilli() code {
+	nanos := cg.gensym(1, 1, call("t.Nanosecond"))
+	millis := cg.gensym(1, 1, binary(ident(nanos), token.QUO, intLit(1000000)))
+	return join(section("writeMillis"), cg.write3DigitsZero(millis))
+}
+
+// ampmName writes the AM/PM indicator from the table t.
+func (cg *CodeGenerator) ampmName(what string, t nameTable) code {
+	hour := cg.gensym(1, 3, call("t.Clock"))
+	var next string
+	if t.width == 0 {
+		next = cg.gensym(1, 1, binary(ident(hour), token.ADD, intLit(12)))
+	}
+	return cg.writeName(what, t, hour, next)
+}
+
+func (cg *CodeGenerator) writeP(upper bool) code {
+	return cg.ampmName("writeP", cg.locale.ampm(upper, false))
+}
+
+func (cg *CodeGenerator) writePC() code {
+	return cg.ampmName("writePC", cg.locale.ampm(false, true))
+}
+
+func (cg *CodeGenerator) writeR() code {
+	minute := cg.gensym(2, 3, call("t.Clock"))
+	second := cg.gensym(3, 3, call("t.Clock"))
+	hour12 := cg.hour12()
+
+	foo := section("writeR")
+	foo = join(foo, cg.write2DigitsZero(hour12))
+	foo = join(foo, cg.writeStringConstant(":"))
+	foo = join(foo, cg.write2DigitsZero(minute))
+	foo = join(foo, cg.writeStringConstant(":"))
+	foo = join(foo, cg.write2DigitsZero(second))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeP(false))
+	return foo
+}
+
+func (cg *CodeGenerator) writeRC() code {
+	hour := cg.gensym(1, 3, call("t.Clock"))
+	minute := cg.gensym(2, 3, call("t.Clock"))
+	foo := section("writeRC")
+	foo = join(foo, cg.write2DigitsZero(hour))
+	foo = join(foo, cg.writeStringConstant(":"))
+	foo = join(foo, cg.write2DigitsZero(minute))
+	return foo
+}
+
+func (cg *CodeGenerator) writeS() code {
+	cg.libraries["strconv"] = struct{}{}
+	cg.maxLength += 20 // longest int64, including its sign
+
+	epoch := cg.gensym(1, 1, call("t.Unix"))
+	epochS := cg.gensym(1, 1, call("strconv.FormatInt", ident(epoch), intLit(10)))
+
+	return join(section("writeS"), cg.writeStringValue(epochS))
+}
+
+func (cg *CodeGenerator) writeSC(pad byte, width int) code {
+	second := cg.gensym(3, 3, call("t.Clock"))
+	return join(section("writeSC"), cg.writeDigits(second, width, pad))
+}
+
+func (cg *CodeGenerator) writeT() code {
+	return join(section("writeT"), cg.writeStringConstant("\t"))
+}
+
+func (cg *CodeGenerator) writeTC() code {
+	hour := cg.gensym(1, 3, call("t.Clock"))
+	minute := cg.gensym(2, 3, call("t.Clock"))
+	second := cg.gensym(3, 3, call("t.Clock"))
+	foo := section("writeTC")
+	foo = join(foo, cg.write2DigitsZero(hour))
+	foo = join(foo, cg.writeStringConstant(":"))
+	foo = join(foo, cg.write2DigitsZero(minute))
+	foo = join(foo, cg.writeStringConstant(":"))
+	foo = join(foo, cg.write2DigitsZero(second))
+	return foo
+}
+
+func (cg *CodeGenerator) writeU() code {
+	cg.tables.u = true
+	cg.maxLength++
+
+	wd := cg.gensym(1, 1, call("t.Weekday"))
+	u := cg.gensym(1, 1, indexExpr(ident("uFromWeekday"), ident(wd)))
+
+	return cg.writeStringValue(u)
+}
+
+// writeUC writes the week number of the year, where weeks start on Sunday,
+// and the days before the first Sunday are in week 0.
+func (cg *CodeGenerator) writeUC(pad byte, width int) code {
+	yearday := cg.gensym(1, 1, call("t.YearDay"))
+	wd := cg.gensym(1, 1, call("t.Weekday"))
+	week := cg.gensym(1, 1, binary(paren(binary(binary(ident(yearday), token.ADD, intLit(6)), token.SUB, call("int", ident(wd)))), token.QUO, intLit(7)))
+	return join(section("writeUC"), cg.writeDigits(week, width, pad))
+}
+
+// writeVC writes the ISO 8601 week number of the year.
+func (cg *CodeGenerator) writeVC(pad byte, width int) code {
+	week := cg.gensym(2, 2, call("t.ISOWeek"))
+	return join(section("writeVC"), cg.writeDigits(week, width, pad))
+}
+
+func (cg *CodeGenerator) writeW() code {
+	cg.tables.w = true
+	cg.maxLength++
+
+	wd := cg.gensym(1, 1, call("t.Weekday"))
+	w := cg.gensym(1, 1, indexExpr(ident("wFromWeekday"), ident(wd)))
+
+	return cg.writeStringValue(w)
+}
+
+// writeWC writes the week number of the year, where weeks start on Monday,
+// and the days before the first Monday are in week 0.
+func (cg *CodeGenerator) writeWC(pad byte, width int) code {
+	yearday := cg.gensym(1, 1, call("t.YearDay"))
+	wd := cg.gensym(1, 1, call("t.Weekday"))
+	monday := binary(paren(binary(call("int", ident(wd)), token.ADD, intLit(6))), token.REM, intLit(7))
+	week := cg.gensym(1, 1, binary(paren(binary(binary(ident(yearday), token.ADD, intLit(6)), token.SUB, monday)), token.QUO, intLit(7)))
+	return join(section("writeWC"), cg.writeDigits(week, width, pad))
+}
+
+func (cg *CodeGenerator) writeY(pad byte, width int) code {
+	year := cg.gensym(1, 3, call("t.Date"))
+	return join(section("writeY"), cg.writeDigits(cg.yearInCentury(year), width, pad))
+}
+
+func (cg *CodeGenerator) writeYC(pad byte, width int, signWidth bool) code {
+	year := cg.gensym(1, 3, call("t.Date"))
+	return join(section("writeYC"), cg.writeYearDigits(year, year, width, pad, signWidth))
+}
+
+// writeZ writes the zone offset like GNU date does for %z, %:z, %::z, and
+// %:::z, where colons is the number of colons: a sign followed by hours and
+// minutes, optionally separated by a colon; hours, minutes, and seconds
+// separated by colons; or only as many of those fields as are needed to write
+// the offset exactly. Unlike time.Format, the sign is that of the offset in
+// seconds.
+func (cg *CodeGenerator) writeZ(colons int) code {
+	maxLength := cg.maxLength
+
+	zoneSeconds := cg.gensym(2, 2, call("t.Zone"))
+	zoneNegative := cg.gensym(1, 1, unary(token.SUB, ident(zoneSeconds)))
+
+	off := cg.useRuntimeOffset()
+
+	writeFields := func(seconds string) code {
+		hours := cg.write2DigitsZero(cg.gensym(1, 1, zoneHoursOf(seconds)))
+		minutes := cg.write2DigitsZero(cg.gensym(1, 1, zoneMinutesOf(seconds)))
+		switch colons {
+		case 0:
+			return join(hours, minutes)
+		case 1:
+			return join(hours, cg.writeByte(':'), minutes)
+		}
+		remainder := cg.write2DigitsZero(cg.gensym(1, 1, zoneSecondsOf(seconds)))
+		if colons == 2 {
+			return join(hours, cg.writeByte(':'), minutes, cg.writeByte(':'), remainder)
+		}
+		return join(hours, ifElse(nonZero(binary(ident(seconds), token.REM, intLit(3600))), join(
+			cg.writeByte(':'), minutes,
+			ifElse(nonZero(zoneSecondsOf(seconds)), join(cg.writeByte(':'), remainder), nil),
+		), nil))
+	}
+
+	foo := join(off, section("writeZ"), ifElse(binary(ident(zoneSeconds), token.GEQ, intLit(0)),
+		join(cg.writeByte('+'), writeFields(zoneSeconds)),
+		join(cg.writeByte('-'), writeFields(zoneNegative))))
+
+	// Only one of the branches is written, and it has a sign and at most
+	// three fields.
+	switch colons {
+	case 0:
+		cg.maxLength = maxLength + len("+hhmm")
+	case 1:
+		cg.maxLength = maxLength + len("+hh:mm")
+	default:
+		cg.maxLength = maxLength + len("+hh:mm:ss")
+	}
+	return foo
+}
+
+// zoneHoursOf returns the hours of the zone offset of seconds.
+func zoneHoursOf(seconds string) ast.Expr {
+	return binary(ident(seconds), token.QUO, intLit(3600))
+}
+
+// zoneMinutesOf returns the minutes after the hours of the zone offset of
+// seconds.
+func zoneMinutesOf(seconds string) ast.Expr {
+	return binary(binary(ident(seconds), token.REM, intLit(3600)), token.QUO, intLit(60))
+}
+
+// zoneSecondsOf returns the seconds after the minutes of the zone offset of
+// seconds.
+func zoneSecondsOf(seconds string) ast.Expr {
+	return binary(ident(seconds), token.REM, intLit(60))
+}
+
+// nonZero returns the condition that x is not zero.
+func nonZero(x ast.Expr) ast.Expr {
+	return binary(x, token.NEQ, intLit(0))
+}
+
+// writeZField writes the zone offset like writeZ, for a %z directive with a
+// field width or the '-' or '_' flag. Like GNU, the offset is then a signed
+// number: the hours are written without leading zeros, and the output is
+// padded to the width of the default form, or to width when it is larger.
+// Padding zeros are written after the sign, padding spaces before it, and the
+// '-' flag writes no padding.
+func (cg *CodeGenerator) writeZField(colons, width int, pad byte) code {
+	maxLength := cg.maxLength
+
+	zoneSeconds := cg.gensym(2, 2, call("t.Zone"))
+	zoneNegative := cg.gensym(1, 1, unary(token.SUB, ident(zoneSeconds)))
+
+	off := cg.useRuntimeOffset()
+
+	// The forms are those of %z, %:z, and %::z, and the hours alone that
+	// %:::z writes for a whole number of hours.
+	writeNumber := func(seconds string, form int) code {
+		if form == 0 {
+			return cg.writeDigits(cg.gensym(1, 1, binary(binary(zoneHoursOf(seconds), token.MUL, intLit(100)), token.ADD, zoneMinutesOf(seconds))), 4, '-')
+		}
+		hours := cg.writeDigits(cg.gensym(1, 1, zoneHoursOf(seconds)), 2, '-')
+		if form == 3 {
+			return hours
+		}
+		minutes := cg.write2DigitsZero(cg.gensym(1, 1, zoneMinutesOf(seconds)))
+		if form == 1 {
+			return join(hours, cg.writeByte(':'), minutes)
+		}
+		remainder := cg.write2DigitsZero(cg.gensym(1, 1, zoneSecondsOf(seconds)))
+		return join(hours, cg.writeByte(':'), minutes, cg.writeByte(':'), remainder)
+	}
+
+	writeForm := func(form int) code {
+		fieldWidth := width
+		if fieldWidth == 0 {
+			fieldWidth = [...]int{len("+hhmm"), len("+hh:mm"), len("+hh:mm:ss"), len("+hh")}[form]
+		}
+		positive := func() ast.Expr {
+			return binary(ident(zoneSeconds), token.GEQ, intLit(0))
+		}
+		switch pad {
+		case '-':
+			return ifElse(positive(),
+				join(cg.writeByte('+'), writeNumber(zoneSeconds, form)),
+				join(cg.writeByte('-'), writeNumber(zoneNegative, form)))
+		case '_':
+			return cg.writeFieldEnd(ifElse(positive(),
+				join(cg.writeByte('+'), writeNumber(zoneSeconds, form)),
+				join(cg.writeByte('-'), writeNumber(zoneNegative, form))), fieldWidth, ' ', false)
+		}
+		return ifElse(positive(),
+			join(cg.writeByte('+'), cg.writeFieldEnd(writeNumber(zoneSeconds, form), fieldWidth-1, '0', false)),
+			join(cg.writeByte('-'), cg.writeFieldEnd(writeNumber(zoneNegative, form), fieldWidth-1, '0', false)))
+	}
+
+	var foo code
+	if colons < 3 {
+		foo = join(off, section("writeZField"), writeForm(colons))
+	} else {
+		foo = join(off, section("writeZField"), ifElse(nonZero(zoneSecondsOf(zoneSeconds)),
+			writeForm(2),
+			ifElse(nonZero(binary(ident(zoneSeconds), token.REM, intLit(3600))), writeForm(1), writeForm(3))))
+	}
+
+	// Only one of the branches is written, and it is either padded to
+	// width, or as long as the default form.
+	length := len("+hhmm")
+	switch colons {
+	case 1:
+		length = len("+hh:mm")
+	case 2, 3:
+		length = len("+hh:mm:ss")
+	}
+	if width > length {
+		length = width
+	}
+	cg.maxLength = maxLength + length
+	return foo
+}
+
+// letterCase specifies how a verb that writes a string value, such as the
+// time zone name, converts the case of its letters.
+type letterCase int
+
+const (
+	caseDefault letterCase = iota
+	caseUpper
+	caseLower
+)
+
+// writeByte writes the byte c, which its caller accounts for in maxLength.
+// Unless appending, the caller must already be using runtime offsets.
+func (cg *CodeGenerator) writeByte(c byte) code {
+	return cg.writeNext(charLit(c))
+}
+
+// writeNext writes the byte x like writeByte writes a constant byte.
+func (cg *CodeGenerator) writeNext(x ast.Expr) code {
+	if cg.useAppend {
+		return appendBytes(x)
+	}
+	return join(assign(bufAt(ident("offset")), token.ASSIGN, x), incDec(ident("offset"), token.INC))
+}
+
+// writeLayoutOffset writes the zone offset like time.Format does for the
+// -0700 and Z0700 families of layout elements: a sign followed by hours, and
+// optionally minutes and seconds, as selected by precision. When colon is
+// true, the fields are separated by colons, and when zulu is true, 'Z' is
+// written instead of a zero offset. Like time.Format, the sign is that of the
+// offset in whole minutes.
+func (cg *CodeGenerator) writeLayoutOffset(zulu, colon bool, precision int) code {
+	cg.maxLength++ // account for the sign
+	if colon {
+		cg.maxLength += precision - 1
+	}
+	if precision > 2 {
+		cg.maxLength++ // account for the sign of seconds of an offset less than a minute
+	}
+	cg.maxLength -= 2 * precision // we only write one of the two branches below
+
+	zoneSeconds := cg.gensym(2, 2, call("t.Zone"))
+	zoneMinutes := cg.gensym(1, 1, binary(ident(zoneSeconds), token.QUO, intLit(60)))
+	zoneNegative := cg.gensym(1, 1, unary(token.SUB, ident(zoneSeconds)))
+
+	off := cg.useRuntimeOffset()
+
+	writeFields := func(sign byte, seconds string) code {
+		foo := cg.writeByte(sign)
+		foo = join(foo, cg.write2DigitsZero(cg.gensym(1, 1, zoneHoursOf(seconds))))
+		if precision > 1 {
+			if colon {
+				foo = join(foo, cg.writeByte(':'))
+			}
+			foo = join(foo, cg.write2DigitsZero(cg.gensym(1, 1, zoneMinutesOf(seconds))))
+		}
+		if precision > 2 {
+			if colon {
+				foo = join(foo, cg.writeByte(':'))
+			}
+			if sign == '+' {
+				// When the offset is less than a minute west of UTC, the
+				// sign is positive, but the seconds are negative.
+				remainder := cg.gensym(1, 1, zoneSecondsOf(seconds))
+				negative := cg.gensym(1, 1, unary(token.SUB, ident(remainder)))
+				maxLength := cg.maxLength
+				foo = join(foo, ifElse(binary(ident(remainder), token.LSS, intLit(0)),
+					join(cg.writeByte('-'), cg.write2DigitsZero(negative)),
+					cg.write2DigitsZero(remainder)))
+				cg.maxLength = maxLength + 2
+			} else {
+				foo = join(foo, cg.write2DigitsZero(cg.gensym(1, 1, zoneSecondsOf(seconds))))
+			}
+		}
+		return foo
+	}
+
+	foo := ifElse(binary(ident(zoneMinutes), token.GEQ, intLit(0)), writeFields('+', zoneSeconds), writeFields('-', zoneNegative))
+	if zulu {
+		foo = ifElse(binary(ident(zoneSeconds), token.EQL, intLit(0)), cg.writeByte('Z'), foo)
+	}
+	return join(off, section("writeLayoutOffset"), foo)
+}
+
+// writeLayoutZone writes the zone abbreviation like time.Format does for the
+// MST layout element, using the zone offset when the zone has no
+// abbreviation.
+func (cg *CodeGenerator) writeLayoutZone() code {
+	return cg.writeZoneOrOffset(caseDefault, func() code {
+		return cg.writeLayoutOffset(false, false, 2)
+	})
+}
+
+// writeZoneOrOffset writes the zone abbreviation with the letter case lc, or
+// the zone offset written by writeOffset when the zone has no abbreviation.
+func (cg *CodeGenerator) writeZoneOrOffset(lc letterCase, writeOffset func() code) code {
+	zoneName := cg.gensym(1, 2, call("t.Zone"))
+
+	off := cg.useRuntimeOffset()
+
+	// The length of the name is accounted for at runtime, so maxLength
+	// only needs room for the offset.
+	name := cg.writeZoneName(zoneName, lc)
+	offset := writeOffset()
+
+	return join(off, section("writeZoneOrOffset"), ifElse(binary(ident(zoneName), token.NEQ, strLit("")), name, offset))
+}
+
+// writeFraction writes the fractional second like time.Format does for the
+// .000 and .999 layout elements: the separator followed by width digits.
+// When trim is true, trailing zeros are removed, and nothing is written when
+// all digits are zero.
+func (cg *CodeGenerator) writeFraction(separator byte, width int, trim bool) code {
+	if !trim {
+		return join(cg.writeStringConstant(string(separator)), cg.writeNC(width))
+	}
+
+	off := cg.useRuntimeOffset()
+
+	if cg.useAppend {
+		last := func() ast.Expr {
+			return binary(lenOf("buf"), token.SUB, intLit(1))
+		}
+		return join(off, section("writeFraction append"), block(
+			define("start", lenOf("buf")),
+			cg.writeStringConstant(string(separator)),
+			cg.writeNC(width),
+			forStmt(nil, and(binary(lenOf("buf"), token.GTR, plus("start", 1)), binary(bufAt(last()), token.EQL, charLit('0'))), nil,
+				assign(ident("buf"), token.ASSIGN, sliceExpr(ident("buf"), nil, last()))),
+			ifElse(binary(lenOf("buf"), token.EQL, plus("start", 1)), assign(ident("buf"), token.ASSIGN, sliceExpr(ident("buf"), nil, ident("start"))), nil)))
+	}
+
+	return join(off, section("writeFraction runtime offset"), block(
+		define("start", ident("offset")),
+		cg.writeStringConstant(string(separator)),
+		cg.writeNC(width),
+		forStmt(nil, and(binary(ident("offset"), token.GTR, plus("start", 1)), binary(bufAt(plus("offset", -1)), token.EQL, charLit('0'))), nil,
+			incDec(ident("offset"), token.DEC)),
+		ifElse(binary(ident("offset"), token.EQL, plus("start", 1)), assign(ident("offset"), token.ASSIGN, ident("start")), nil)))
+}
+
+// writeZC writes the zone according to the zone style of the generator, with
+// the letter case lc.
+func (cg *CodeGenerator) writeZC(lc letterCase) code {
+	switch cg.zoneStyle {
+	case ZoneAbbreviationOrOffset:
+		return cg.writeZoneOrOffset(lc, func() code {
+			return cg.writeZ(0)
+		})
+	case ZoneLocation:
+		return cg.writeZoneName(cg.gensym(1, 1, method(call("t.Location"), "String")), lc)
+	}
+	return cg.writeZoneName(cg.gensym(1, 2, call("t.Zone")), lc)
+}
+
+// writeZoneName writes the string zoneName with the letter case lc. Because
+// zone names have no maximum length, its length is added to the buffer size
+// at runtime rather than to maxLength.
+func (cg *CodeGenerator) writeZoneName(zoneName string, lc letterCase) code {
+	cg.dynamicLengths = append(cg.dynamicLengths, zoneName)
+
+	if lc == caseDefault {
+		return cg.writeStringValue(zoneName)
+	}
+
+	var from, to, delta byte = 'a', 'z', 'a' - 'A'
+	if lc == caseLower {
+		from, to = 'A', 'Z'
+	}
+	op := token.ADD
+	if lc == caseUpper {
+		op = token.SUB
+	}
+
+	loop := func(body code) code {
+		return forStmt(define("i", intLit(0)), binary(ident("i"), token.LSS, lenOf(zoneName)), incDec(ident("i"), token.INC), body)
+	}
+	isLetter := and(binary(ident("c"), token.GEQ, charLit(from)), binary(ident("c"), token.LEQ, charLit(to)))
+	converted := binary(ident("c"), op, intLit(int(delta)))
+
+	off := cg.useRuntimeOffset()
+
+	if cg.useAppend {
+		return join(section("writeZoneName append with case conversion"), loop(
+			ifInit(define("c", indexExpr(ident(zoneName), ident("i"))), isLetter, appendBytes(converted), appendBytes(ident("c")))))
+	}
+
+	return join(off, section("writeZoneName runtime offset with case conversion"), loop(join(
+		ifInit(define("c", indexExpr(ident(zoneName), ident("i"))), isLetter,
+			assign(bufAt(ident("offset")), token.ASSIGN, converted),
+			assign(bufAt(ident("offset")), token.ASSIGN, ident("c"))),
+		incDec(ident("offset"), token.INC))))
+}
+
+func (cg *CodeGenerator) writeTZ() code {
+	cg.maxLength += 2 // account for sign and colon
+	cg.maxLength -= 4 // we only write 4 digits, even though we write code to handle digits
+
+	zoneSeconds := cg.gensym(2, 2, call("t.Zone"))
+
+	zoneHourPositive := cg.gensym(1, 1, zoneHoursOf(zoneSeconds))
+	zoneMinutePositive := cg.gensym(1, 1, zoneMinutesOf(zoneSeconds))
+
+	zoneNegative := cg.gensym(1, 1, unary(token.SUB, ident(zoneSeconds)))
+	zoneHourNegative := cg.gensym(1, 1, zoneHoursOf(zoneNegative))
+	zoneMinuteNegative := cg.gensym(1, 1, zoneMinutesOf(zoneNegative))
+
+	off := cg.useRuntimeOffset()
+
+	return join(off, section("writeTZ"), ifElse(binary(ident(zoneSeconds), token.EQL, intLit(0)),
+		cg.writeByte('Z'),
+		ifElse(binary(ident(zoneSeconds), token.GTR, intLit(0)),
+			join(cg.writeByte('+'), cg.write2DigitsZero(zoneHourPositive), cg.writeByte(':'), cg.write2DigitsZero(zoneMinutePositive)),
+			join(cg.writeByte('-'), cg.write2DigitsZero(zoneHourNegative), cg.writeByte(':'), cg.write2DigitsZero(zoneMinuteNegative)))))
+}
+
+func (cg *CodeGenerator) writePercent() code {
+	return cg.writeStringConstant("%")
+}
+
+func (cg *CodeGenerator) writePlus(upper bool) code {
+	lc := caseDefault
+	if upper {
+		lc = caseUpper
+	}
+	foo := section("writePlus")
+	foo = join(foo, cg.writeWeekdayShort(upper))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeMonthShort(upper))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeE('_', 2))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeTC())
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeP(false))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeZC(lc))
+	foo = join(foo, cg.writeStringConstant(" "))
+	foo = join(foo, cg.writeYC('0', 4, false))
+	return foo
+}
+
+// tables records which lookup tables are referenced by emitted code.
+type tables struct {
+	digits, digitPairs, u, w bool
+
+	// names holds the tables of names, in the order they were first
+	// referenced.
+	names []nameTable
+}
+
+// declare records that the table of names t is referenced.
+func (t *tables) declare(n nameTable) {
+	for _, other := range t.names {
+		if other.constant == n.constant {
+			return
+		}
+	}
+	t.names = append(t.names, n)
+}
+
+// merge records that the lookup tables referenced by other are also
+// referenced.
+func (t *tables) merge(other tables) {
+	t.digits = t.digits || other.digits
+	t.digitPairs = t.digitPairs || other.digitPairs
+	t.u = t.u || other.u
+	t.w = t.w || other.w
+	for _, n := range other.names {
+		t.declare(n)
+	}
+}
+
+// hoistedName returns the name of the lookup table name when it is declared
+// at package level.
+func hoistedName(name string) string {
+	return "sft" + strings.ToUpper(name[:1]) + name[1:]
+}
+
+// identifiers returns the names of the lookup tables in t.
+func (t tables) identifiers() map[string]bool {
+	ids := map[string]bool{
+		"digits":       t.digits,
+		"digitPairs":   t.digitPairs,
+		"uFromWeekday": t.u,
+		"wFromWeekday": t.w,
+	}
+	for _, n := range t.names {
+		ids[n.constant] = true
+		ids[n.indices] = true
+	}
+	return ids
+}
+
+// hoistTables renames the references of body to the lookup tables in t to
+// the names of the tables declared at package level. The variables of body
+// that have the same names are consistently renamed as well.
+func hoistTables(body code, t tables) {
+	ids := t.identifiers()
+	var rename func(n ast.Node) bool
+	rename = func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			ast.Inspect(n.X, rename) // the selected name is not a table
+			return false
+		case *ast.Ident:
+			if ids[n.Name] {
+				n.Name = hoistedName(n.Name)
+			}
+		}
+		return true
+	}
+	ast.Inspect(&ast.BlockStmt{List: body}, rename)
+}
+
+// tableDecls returns the declarations of the lookup tables in t. The
+// declarations are valid both inside a function and at package level, where
+// they are named by hoistedName when hoisted is true.
+func tableDecls(t tables, hoisted bool) []*ast.GenDecl {
+	names := make([]nameTable, len(t.names))
+	copy(names, t.names)
+	sort.Slice(names, func(i, j int) bool { return names[i].constant < names[j].constant })
+
+	name := func(n string) string {
+		if hoisted {
+			return hoistedName(n)
+		}
+		return n
+	}
+
+	var decls []*ast.GenDecl
+
+	// stringSlice declares the variable n as the slice of the strings ss.
+	stringSlice := func(n string, ss ...string) {
+		elts := make([]ast.Expr, len(ss))
+		for i, s := range ss {
+			elts[i] = strLit(s)
+		}
+		decls = append(decls, valueDecl(token.VAR, name(n), compositeLit(arrayOf(nil, ident("string")), elts...)))
+	}
+
+	// declareNames declares the tables of names whose constants start with
+	// prefix, followed by their indices.
+	declareNames := func(prefix string) {
+		declared := make(map[string]struct{})
+		for _, n := range names {
+			if strings.HasPrefix(n.constant, prefix) {
+				decls = append(decls, valueDecl(token.CONST, name(n.constant), strLit(n.text)))
+			}
+		}
+		for _, n := range names {
+			if _, ok := declared[n.indices]; ok || !strings.HasPrefix(n.constant, prefix) {
+				continue
+			}
+			declared[n.indices] = struct{}{}
+			offsets := make([]ast.Expr, len(n.offsets))
+			for i, offset := range n.offsets {
+				offsets[i] = intLit(offset)
+			}
+			decls = append(decls, valueDecl(token.VAR, name(n.indices), compositeLit(arrayOf(nil, ident("int")), offsets...)))
+		}
+	}
+
+	declareNames("ampm")
+	if t.digits {
+		decls = append(decls, valueDecl(token.CONST, name("digits"), strLit("0123456789 123456789")))
+	}
+	if t.digitPairs {
+		pairs := make([]byte, 0, 200)
+		for i := 0; i < 100; i++ {
+			pairs = append(pairs, byte('0'+i/10), byte('0'+i%10))
+		}
+		decls = append(decls, valueDecl(token.CONST, name("digitPairs"), strLit(string(pairs))))
+	}
+	declareNames("weekdays")
+	declareNames("months")
+	if t.u {
+		stringSlice("uFromWeekday", "7", "1", "2", "3", "4", "5", "6")
+	}
+	if t.w {
+		stringSlice("wFromWeekday", "0", "1", "2", "3", "4", "5", "6")
+	}
+	return decls
+}
+
+// declareTables returns the statements that declare the lookup tables in t
+// inside a function.
+func declareTables(t tables) code {
+	var body code
+	for _, decl := range tableDecls(t, false) {
+		body = append(body, &ast.DeclStmt{Decl: decl})
+	}
+	return body
+}
+
+func appendString(buf *[]byte, f string, a ...interface{}) {
+	*buf = append(*buf, fmt.Sprintf(f, a...)...)
+}
+
+func appendRune(buf *[]byte, r rune) {
+	if r < utf8.RuneSelf {
+		*buf = append(*buf, byte(r))
+		return
+	}
+	olen := len(*buf)
+	*buf = append(*buf, 0, 0, 0, 0)              // grow buf large enough to accommodate largest possible UTF8 sequence
+	n := utf8.EncodeRune((*buf)[olen:olen+4], r) // encode rune into newly allocated buf space
+	*buf = (*buf)[:olen+n]                       // trim buf to actual size used by rune addition
+}
+
+// gofmt returns source formatted like gofmt formats it.
+func gofmt(source []byte) ([]byte, error) {
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "", string(source), parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	bb := new(bytes.Buffer)
+	if err := format.Node(bb, fs, f); err != nil {
+		return nil, err
+	}
+	return bb.Bytes(), nil
+}
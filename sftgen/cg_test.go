@@ -0,0 +1,401 @@
+package sftgen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewCodeGeneratorRequiresSpec(t *testing.T) {
+	_, err := NewCodeGenerator("", nil)
+	if err == nil {
+		t.Fatal("GOT: nil error; WANT: error")
+	}
+}
+
+func TestNewCodeGeneratorDefaults(t *testing.T) {
+	cg, err := NewCodeGenerator("%F %T", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"package main\n",
+		"func formatTime(buf []byte, t time.Time) []byte {\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func TestNewCodeGeneratorExtraVerbs(t *testing.T) {
+	_, err := NewCodeGenerator("%T%1", &Config{})
+	if got, want := err, "cannot recognize format verb '1' at index 3"; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+
+	_, err = NewCodeGenerator("%T%1", &Config{AllowExtra: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewCodeGeneratorParse(t *testing.T) {
+	cg, err := NewCodeGenerator("%a, %d %b %Y %T %z", &Config{
+		Package:       "timefmt",
+		FuncName:      "appendTime",
+		ParseFuncName: "parseTime",
+		UseAppend:     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"package timefmt\n",
+		"func appendTime(buf []byte, t time.Time) []byte {\n",
+		"func parseTime(b []byte) (time.Time, error) {\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	for _, c := range []struct {
+		spec string
+		want string
+	}{
+		{"%G-%m", "cannot generate parser for format verb 'G' at index 1"},
+		{"%F %U", "cannot generate parser for format verb 'U' at index 4"},
+		{"%Y-W%V", "cannot generate parser for format verb 'V' at index 5"},
+		{"%Y %_3W", "cannot generate parser for format verb 'W' at index 6"},
+	} {
+		_, err = NewCodeGenerator(c.spec, &Config{ParseFuncName: "parseTime"})
+		if got := err; got == nil || got.Error() != c.want {
+			t.Errorf("%q: GOT: %v; WANT: %q", c.spec, got, c.want)
+		}
+	}
+}
+
+func TestNewCodeGeneratorFlags(t *testing.T) {
+	cg, err := NewCodeGenerator("%-d %_j %^a %#B %#Z", &Config{
+		ParseFuncName: "parseTime",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(cg.directives), 9; got != want {
+		t.Fatalf("GOT: %v; WANT: %v", got, want)
+	}
+	if got, want := cg.directives[0].pad, byte('-'); got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+	if got, want := cg.directives[2].pad, byte('_'); got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+	if got, want := cg.directives[4].upcase, true; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	if got, want := cg.directives[6].swapcase, true; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"weekdaysLongUpper",
+		"monthsLongUpper",
+		"buf[i] = ' '",
+		"c + 32",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func TestScanSpecWidths(t *testing.T) {
+	directives, err := scanSpec("%3N%010d%-5H%T.%3 %1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []directive{
+		{verb: 'N', index: 2, width: 3},
+		{verb: 'd', index: 7, width: 10, pad: '0'},
+		{verb: 'H', index: 11, width: 5, pad: '-'},
+		{verb: 'T', index: 13},
+		{literal: "."},
+		{verb: '3', index: 16},
+		{literal: " "},
+		{verb: '1', index: 19},
+	}
+	if got, want := len(directives), len(want); got != want {
+		t.Fatalf("GOT: %v; WANT: %v", got, want)
+	}
+	for i := range want {
+		if got, want := directives[i], want[i]; got != want {
+			t.Errorf("GOT: %#v; WANT: %#v", got, want)
+		}
+	}
+
+	// Like GNU, widths of %N beyond the 9 digits of a nanosecond pad it
+	// on the right.
+	if _, err = NewCodeGenerator("%10N", nil); err != nil {
+		t.Errorf("GOT: %v; WANT: %v", err, nil)
+	}
+}
+
+func TestNewCodeGeneratorFixedWidth(t *testing.T) {
+	cg, err := NewCodeGenerator("%H:%M UTC%%%n", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"_ = buf[10]\n",
+		"buf[2] = ':'\n",
+		"copy(buf[5:11], \" UTC%\\n\")\n",
+		"buf = buf[:11]\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	// Once an offset is only known at runtime, there is no single bounds
+	// check that covers every store.
+	cg, err = NewCodeGenerator("%H:%M %Z", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), "_ = buf["; strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: no %q", got, want)
+	}
+
+	// Years from 0 through 9999 are written with fixed width, in a fast
+	// path ahead of the one that writes any year.
+	cg, err = NewCodeGenerator("%F %T", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = cg.String()
+	for _, want := range []string{
+		"\tif gs0 >= 0 && gs0 < 10000 {\n\t\t_ = buf[18]\n",
+		"buf[4] = '-'\n",
+		"return buf[:19]\n\t}\n",
+		"offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	// A year without padding has no fixed width.
+	cg, err = NewCodeGenerator("%-Y-%m-%d", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), "_ = buf["; strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: no %q", got, want)
+	}
+}
+
+func TestNewCodeGeneratorWeekVerbs(t *testing.T) {
+	cg, err := NewCodeGenerator("%G-W%V %U %W", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	if got, want := strings.Count(got, "t.ISOWeek()"), 1; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	if got, want := strings.Count(got, "t.Weekday()"), 1; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func TestNewCodeGeneratorAppend(t *testing.T) {
+	cg, err := NewCodeGenerator("%F %T", &Config{EmitMain: true, UseAppend: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	if want := "buf = buf[:0]"; strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: no %q", got, want)
+	}
+	if want := "formatTime(make([]byte, 0, 128), when)"; !strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	cg, err = NewCodeGenerator("%F %T", &Config{UseAppend: true, Truncate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), "buf = buf[:0]"; !strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+}
+
+func TestNewCodeGeneratorWriter(t *testing.T) {
+	cg, err := NewCodeGenerator("%F %T", &Config{FuncName: "writeTime", UseWriter: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"\t\"bufio\"\n",
+		"func writeTime(w *bufio.Writer, t time.Time) error {\n",
+		"buf := w.AvailableBuffer()\n",
+		"_, err := w.Write(buf)\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func TestNewCodeGeneratorZoneStyle(t *testing.T) {
+	cg, err := NewCodeGenerator("%H %Z", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), "if n := 3 + len(gs1); len(buf) < n {\n"; !strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	cg, err = NewCodeGenerator("%Z", &Config{ZoneStyle: ZoneAbbreviationOrOffset})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), "if gs0 != \"\" {\n"; !strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	cg, err = NewCodeGenerator("%F %T %Z", &Config{ParseFuncName: "parseTime", ZoneStyle: ZoneLocation})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		":= t.Location().String()\n",
+		"location, err := time.LoadLocation(string(zoneName))\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func TestScanSpecColons(t *testing.T) {
+	directives, err := scanSpec("%:z %_10::z %:::z %8:z %_5:::z %3:%z")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []directive{
+		{verb: 'z', index: 2, colons: 1},
+		{literal: " "},
+		{verb: 'z', index: 10, colons: 2, pad: '_', width: 10},
+		{literal: " "},
+		{verb: 'z', index: 16, colons: 3},
+		{literal: " "},
+		{verb: 'z', index: 21, colons: 1, width: 8},
+		{literal: " "},
+		{verb: 'z', index: 29, colons: 3, pad: '_', width: 5},
+		{literal: " "},
+		{verb: '3', index: 32},
+		{literal: ":"},
+		{verb: 'z', index: 35},
+	}
+	if got, want := len(directives), len(want); got != want {
+		t.Fatalf("GOT: %v; WANT: %v", got, want)
+	}
+	for i := range want {
+		if got, want := directives[i], want[i]; got != want {
+			t.Errorf("GOT: %#v; WANT: %#v", got, want)
+		}
+	}
+
+	for spec, want := range map[string]string{
+		"%:Z":    "cannot use colons with format verb 'Z' at index 2",
+		"%::::z": "cannot use 4 colons with format verb 'z' at index 5",
+		"%F %:":  "cannot find closing format verb",
+	} {
+		if _, err := scanSpec(spec); err == nil || err.Error() != want {
+			t.Errorf("%q: GOT: %v; WANT: %q", spec, err, want)
+		}
+	}
+}
+
+func TestNewCodeGeneratorDigitPairs(t *testing.T) {
+	cg, err := NewCodeGenerator("%H:%M %j", &Config{DigitPairs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"const digitPairs = \"000102",
+		"buf[0] = digitPairs[2*gs0]\n",
+		"buf[1] = digitPairs[2*gs0+1]\n",
+		"buf[6] = digitPairs[2*quotient+1]\n",
+		"buf[8] = digitPairs[2*remainder+1]\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+	if want := "const digits ="; strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: no %q", got, want)
+	}
+
+	// Two digit numbers need no division, nor the variables that hold
+	// its results.
+	cg, err = NewCodeGenerator("%H:%M", &Config{DigitPairs: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), "var quotient"; strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: no %q", got, want)
+	}
+}
+
+func TestNewCodeGeneratorLengths(t *testing.T) {
+	cases := []struct {
+		spec      string
+		config    Config
+		maxLength int
+		fixed     bool
+	}{
+		{"%H:%M UTC%%%n", Config{}, 11, true},
+		{"%H:%M UTC%%%n", Config{UseAppend: true}, 11, true},
+		{"%a %T.%3", Config{AllowExtra: true, UseWriter: true}, 16, true},
+		{"%-d/%-m", Config{UseAppend: true}, 5, false},
+		{"%H %Z", Config{}, -1, false}, // zone names have no maximum length
+		{"%H %Z", Config{ZoneStyle: ZoneLocation}, -1, false},
+		{"%H:%M", Config{Duration: true}, 24, false},
+	}
+
+	for _, c := range cases {
+		cg, err := NewCodeGenerator(c.spec, &c.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := cg.MaxLength(), c.maxLength; got != want {
+			t.Errorf("%q: GOT: %v; WANT: %v", c.spec, got, want)
+		}
+		if got, want := cg.IsFixedWidth(), c.fixed; got != want {
+			t.Errorf("%q: GOT: %v; WANT: %v", c.spec, got, want)
+		}
+
+		got := cg.String()
+		want := fmt.Sprintf("const %sMaxLen = %d\n", cg.functionName, c.maxLength)
+		if c.maxLength < 0 {
+			want = "MaxLen"
+		}
+		if strings.Contains(got, want) != (c.maxLength > 0) {
+			t.Errorf("%q: GOT: %q; WANT: %q %v", c.spec, got, want, c.maxLength > 0)
+		}
+		want = fmt.Sprintf("const %sFixedLen = %d\n", cg.functionName, c.maxLength)
+		if strings.Contains(got, want) != c.fixed {
+			t.Errorf("%q: GOT: %q; WANT: %q %v", c.spec, got, want, c.fixed)
+		}
+	}
+}
@@ -0,0 +1,516 @@
+package sftgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// conformanceSpecs together use every formatting verb, along with the GNU
+// flags and field widths.
+var conformanceSpecs = []string{
+	"%a|%A|%b|%B|%c|%C|%d|%D|%e|%F|%g|%G|%h|%H|%I|%j|%k|%l|%m|%M|%n|%N|%p|%P|%r|%R|%s|%S|%t|%T|%u|%U|%V|%w|%W|%x|%X|%y|%Y|%z|%Z|%%",
+	"%+|%1|%2|%3|%4|%10N|%_12N|%-12N",
+	"%-I|%_I|%-l|%0l|%^p|%#p|%^P|%-j|%_y|%^a|%#A|%^B|%#Z|%12s|%-H|%_M|%030c|%-10Z",
+	"%Z|%^Z|%#Z|%z|%:z|%::z|%:::z",
+	"%_10Y|%010Y|%-Y|%_Y|%3Y|%_C|%-C|%4C|%6G|%_6G|%13s|%_14s",
+	"%-z|%_z|%-:z|%_:z|%-::z|%_::z|%-:::z|%_:::z|%10z|%_10z|%10:z|%_12::z|%10:::z|%8:z|%_5:z|%9::z|%_8:::z|%8:::z|%1:z",
+	"%-N|%_N|%-3N|%_3N|%2Y|%1Y|%_2Y|%-2Y|%2G|%_3G",
+}
+
+// conformanceCases lists edge instants, as Go expressions, along with the
+// text that each of the conformanceSpecs must produce for them. Expected
+// values were produced by GNU date, except for %+ and the extra verbs, which
+// it does not support, except that it writes year 0 as "0" for %c, and except
+// that it writes all nine digits for a %-N without a field width, where
+// strftime removes their trailing zeros like it does for %-9N. Years
+// outside of 0 through 9999 are written like time.Format writes them, unless
+// a field width is given, and the century is that of the year truncated
+// toward zero, with the sign of the year.
+var conformanceCases = []struct {
+	name string
+	when string
+	want []string
+}{
+	{
+		name: "midnight",
+		when: "time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)",
+		want: []string{
+			"Mon|Monday|Jan|January|Mon Jan  2 00:00:00 2006|20|02|01/02/06| 2|2006-01-02|06|2006|Jan|00|12|002| 0|12|01|00|\n|000000000|AM|am|12:00:00 AM|00:00|1136160000|00|\t|00:00:00|1|01|01|1|01|01/02/06|00:00:00|06|2006|+0000|UTC|%",
+			"Mon Jan  2 00:00:00 AM UTC 2006|Z|12|000|000000|0000000000|0           |0",
+			"12|12|12|12|AM|am|am|2| 6|MON|MONDAY|JANUARY|utc|001136160000|0| 0|000000Mon Jan  2 00:00:00 2006|UTC",
+			"UTC|UTC|utc|+0000|+00:00|+00:00:00|+00",
+			"      2006|0000002006|2006|2006|2006|20|20|0020|002006|  2006|0001136160000|    1136160000",
+			"+0|   +0|+0:00| +0:00|+0:00:00| +0:00:00|+0| +0|+000000000|        +0|+000000:00|    +0:00:00|+000000000|+0000:00|+0:00|+00:00:00|      +0|+0000000|+0:00",
+			"0|0        |0|0  |2006|2006|2006|2006|2006|2006",
+		},
+	},
+	{
+		name: "noon",
+		when: "time.Date(2006, time.January, 2, 12, 0, 0, 500000000, time.UTC)",
+		want: []string{
+			"Mon|Monday|Jan|January|Mon Jan  2 12:00:00 2006|20|02|01/02/06| 2|2006-01-02|06|2006|Jan|12|12|002|12|12|01|00|\n|500000000|PM|pm|12:00:00 PM|12:00|1136203200|00|\t|12:00:00|1|01|01|1|01|01/02/06|12:00:00|06|2006|+0000|UTC|%",
+			"Mon Jan  2 12:00:00 PM UTC 2006|Z|12|500|500000|5000000000|5           |5",
+			"12|12|12|12|PM|pm|pm|2| 6|MON|MONDAY|JANUARY|utc|001136203200|12| 0|000000Mon Jan  2 12:00:00 2006|UTC",
+			"UTC|UTC|utc|+0000|+00:00|+00:00:00|+00",
+			"      2006|0000002006|2006|2006|2006|20|20|0020|002006|  2006|0001136203200|    1136203200",
+			"+0|   +0|+0:00| +0:00|+0:00:00| +0:00:00|+0| +0|+000000000|        +0|+000000:00|    +0:00:00|+000000000|+0000:00|+0:00|+00:00:00|      +0|+0000000|+0:00",
+			"5|5        |5|5  |2006|2006|2006|2006|2006|2006",
+		},
+	},
+	{
+		name: "leap day",
+		when: "time.Date(2024, time.February, 29, 23, 59, 59, 999999999, time.UTC)",
+		want: []string{
+			"Thu|Thursday|Feb|February|Thu Feb 29 23:59:59 2024|20|29|02/29/24|29|2024-02-29|24|2024|Feb|23|11|060|23|11|02|59|\n|999999999|PM|pm|11:59:59 PM|23:59|1709251199|59|\t|23:59:59|4|08|09|4|09|02/29/24|23:59:59|24|2024|+0000|UTC|%",
+			"Thu Feb 29 23:59:59 PM UTC 2024|Z|11|999|999999|9999999990|999999999   |999999999",
+			"11|11|11|11|PM|pm|pm|60|24|THU|THURSDAY|FEBRUARY|utc|001709251199|23|59|000000Thu Feb 29 23:59:59 2024|UTC",
+			"UTC|UTC|utc|+0000|+00:00|+00:00:00|+00",
+			"      2024|0000002024|2024|2024|2024|20|20|0020|002024|  2024|0001709251199|    1709251199",
+			"+0|   +0|+0:00| +0:00|+0:00:00| +0:00:00|+0| +0|+000000000|        +0|+000000:00|    +0:00:00|+000000000|+0000:00|+0:00|+00:00:00|      +0|+0000000|+0:00",
+			"999999999|999999999|999|999|2024|2024|2024|2024|2024|2024",
+		},
+	},
+	{
+		name: "year 0",
+		when: "time.Date(0, time.June, 15, 8, 9, 10, 11, time.UTC)",
+		want: []string{
+			"Thu|Thursday|Jun|June|Thu Jun 15 08:09:10 0000|00|15|06/15/00|15|0000-06-15|00|0000|Jun|08|08|167| 8| 8|06|09|\n|000000011|AM|am|08:09:10 AM|08:09|-62152847450|10|\t|08:09:10|4|24|24|4|24|06/15/00|08:09:10|00|0000|+0000|UTC|%",
+			"Thu Jun 15 08:09:10 AM UTC 0000|Z|8|000|000000|0000000110|000000011   |000000011",
+			"8| 8|8|08|AM|am|am|167| 0|THU|THURSDAY|JUNE|utc|-62152847450|8| 9|000000Thu Jun 15 08:09:10 0000|UTC",
+			"UTC|UTC|utc|+0000|+00:00|+00:00:00|+00",
+			"         0|0000000000|0|   0|000| 0|0|0000|000000|     0|-062152847450|  -62152847450",
+			"+0|   +0|+0:00| +0:00|+0:00:00| +0:00:00|+0| +0|+000000000|        +0|+000000:00|    +0:00:00|+000000000|+0000:00|+0:00|+00:00:00|      +0|+0000000|+0:00",
+			"000000011|000000011|0|0  |00|0| 0|0|00|  0",
+		},
+	},
+	{
+		name: "negative year",
+		when: "time.Date(-44, time.March, 15, 12, 30, 0, 0, time.UTC)",
+		want: []string{
+			"Thu|Thursday|Mar|March|Thu Mar 15 12:30:00 -0044|-0|15|03/15/44|15|-0044-03-15|44|-0044|Mar|12|12|075|12|12|03|30|\n|000000000|PM|pm|12:30:00 PM|12:30|-63549315000|00|\t|12:30:00|4|11|11|4|11|03/15/44|12:30:00|44|-0044|+0000|UTC|%",
+			"Thu Mar 15 12:30:00 PM UTC -0044|Z|12|000|000000|0000000000|0           |0",
+			"12|12|12|12|PM|pm|pm|75|44|THU|THURSDAY|MARCH|utc|-63549315000|12|30|00000Thu Mar 15 12:30:00 -0044|UTC",
+			"UTC|UTC|utc|+0000|+00:00|+00:00:00|+00",
+			"       -44|-000000044|-44|  -44|-44|-0|-0|-000|-00044|   -44|-063549315000|  -63549315000",
+			"+0|   +0|+0:00| +0:00|+0:00:00| +0:00:00|+0| +0|+000000000|        +0|+000000:00|    +0:00:00|+000000000|+0000:00|+0:00|+00:00:00|      +0|+0000000|+0:00",
+			"0|0        |0|0  |-44|-44|-44|-44|-44|-44",
+		},
+	},
+	{
+		name: "negative century",
+		when: "time.Date(-150, time.June, 15, 9, 8, 7, 0, time.UTC)",
+		want: []string{
+			"Sat|Saturday|Jun|June|Sat Jun 15 09:08:07 -0150|-1|15|06/15/50|15|-0150-06-15|50|-0150|Jun|09|09|166| 9| 9|06|08|\n|000000000|AM|am|09:08:07 AM|09:08|-66886440713|07|\t|09:08:07|6|23|24|6|23|06/15/50|09:08:07|50|-0150|+0000|UTC|%",
+			"Sat Jun 15 09:08:07 AM UTC -0150|Z|9|000|000000|0000000000|0           |0",
+			"9| 9|9|09|AM|am|am|166|50|SAT|SATURDAY|JUNE|utc|-66886440713|9| 8|00000Sat Jun 15 09:08:07 -0150|UTC",
+			"UTC|UTC|utc|+0000|+00:00|+00:00:00|+00",
+			"      -150|-000000150|-150| -150|-150|-1|-1|-001|-00150|  -150|-066886440713|  -66886440713",
+			"+0|   +0|+0:00| +0:00|+0:00:00| +0:00:00|+0| +0|+000000000|        +0|+000000:00|    +0:00:00|+000000000|+0000:00|+0:00|+00:00:00|      +0|+0000000|+0:00",
+			"0|0        |0|0  |-150|-150|-150|-150|-150|-150",
+		},
+	},
+	{
+		name: "year 10000",
+		when: "time.Date(10000, time.January, 1, 0, 0, 0, 0, time.UTC)",
+		want: []string{
+			"Sat|Saturday|Jan|January|Sat Jan  1 00:00:00 10000|100|01|01/01/00| 1|10000-01-01|99|9999|Jan|00|12|001| 0|12|01|00|\n|000000000|AM|am|12:00:00 AM|00:00|253402300800|00|\t|00:00:00|6|00|52|6|00|01/01/00|00:00:00|00|10000|+0000|UTC|%",
+			"Sat Jan  1 00:00:00 AM UTC 10000|Z|12|000|000000|0000000000|0           |0",
+			"12|12|12|12|AM|am|am|1| 0|SAT|SATURDAY|JANUARY|utc|253402300800|0| 0|00000Sat Jan  1 00:00:00 10000|UTC",
+			"UTC|UTC|utc|+0000|+00:00|+00:00:00|+00",
+			"     10000|0000010000|10000|10000|10000|100|100|0100|009999|  9999|0253402300800|  253402300800",
+			"+0|   +0|+0:00| +0:00|+0:00:00| +0:00:00|+0| +0|+000000000|        +0|+000000:00|    +0:00:00|+000000000|+0000:00|+0:00|+00:00:00|      +0|+0000000|+0:00",
+			"0|0        |0|0  |10000|10000|10000|10000|9999|9999",
+		},
+	},
+	{
+		name: "before spring forward",
+		when: "time.Unix(1615705199, 0).In(newYork)",
+		want: []string{
+			"Sun|Sunday|Mar|March|Sun Mar 14 01:59:59 2021|20|14|03/14/21|14|2021-03-14|21|2021|Mar|01|01|073| 1| 1|03|59|\n|000000000|AM|am|01:59:59 AM|01:59|1615705199|59|\t|01:59:59|7|11|10|0|10|03/14/21|01:59:59|21|2021|-0500|EST|%",
+			"Sun Mar 14 01:59:59 AM EST 2021|-05:00|1|000|000000|0000000000|0           |0",
+			"1| 1|1|01|AM|am|am|73|21|SUN|SUNDAY|MARCH|est|001615705199|1|59|000000Sun Mar 14 01:59:59 2021|EST",
+			"EST|EST|est|-0500|-05:00|-05:00:00|-05",
+			"      2021|0000002021|2021|2021|2021|20|20|0020|002021|  2021|0001615705199|    1615705199",
+			"-500| -500|-5:00| -5:00|-5:00:00| -5:00:00|-5| -5|-000000500|      -500|-000005:00|    -5:00:00|-000000005|-0005:00|-5:00|-05:00:00|      -5|-0000005|-5:00",
+			"0|0        |0|0  |2021|2021|2021|2021|2021|2021",
+		},
+	},
+	{
+		name: "after spring forward",
+		when: "time.Unix(1615705200, 0).In(newYork)",
+		want: []string{
+			"Sun|Sunday|Mar|March|Sun Mar 14 03:00:00 2021|20|14|03/14/21|14|2021-03-14|21|2021|Mar|03|03|073| 3| 3|03|00|\n|000000000|AM|am|03:00:00 AM|03:00|1615705200|00|\t|03:00:00|7|11|10|0|10|03/14/21|03:00:00|21|2021|-0400|EDT|%",
+			"Sun Mar 14 03:00:00 AM EDT 2021|-04:00|3|000|000000|0000000000|0           |0",
+			"3| 3|3|03|AM|am|am|73|21|SUN|SUNDAY|MARCH|edt|001615705200|3| 0|000000Sun Mar 14 03:00:00 2021|EDT",
+			"EDT|EDT|edt|-0400|-04:00|-04:00:00|-04",
+			"      2021|0000002021|2021|2021|2021|20|20|0020|002021|  2021|0001615705200|    1615705200",
+			"-400| -400|-4:00| -4:00|-4:00:00| -4:00:00|-4| -4|-000000400|      -400|-000004:00|    -4:00:00|-000000004|-0004:00|-4:00|-04:00:00|      -4|-0000004|-4:00",
+			"0|0        |0|0  |2021|2021|2021|2021|2021|2021",
+		},
+	},
+	{
+		name: "before fall back",
+		when: "time.Unix(1636263000, 0).In(newYork)",
+		want: []string{
+			"Sun|Sunday|Nov|November|Sun Nov  7 01:30:00 2021|20|07|11/07/21| 7|2021-11-07|21|2021|Nov|01|01|311| 1| 1|11|30|\n|000000000|AM|am|01:30:00 AM|01:30|1636263000|00|\t|01:30:00|7|45|44|0|44|11/07/21|01:30:00|21|2021|-0400|EDT|%",
+			"Sun Nov  7 01:30:00 AM EDT 2021|-04:00|1|000|000000|0000000000|0           |0",
+			"1| 1|1|01|AM|am|am|311|21|SUN|SUNDAY|NOVEMBER|edt|001636263000|1|30|000000Sun Nov  7 01:30:00 2021|EDT",
+			"EDT|EDT|edt|-0400|-04:00|-04:00:00|-04",
+			"      2021|0000002021|2021|2021|2021|20|20|0020|002021|  2021|0001636263000|    1636263000",
+			"-400| -400|-4:00| -4:00|-4:00:00| -4:00:00|-4| -4|-000000400|      -400|-000004:00|    -4:00:00|-000000004|-0004:00|-4:00|-04:00:00|      -4|-0000004|-4:00",
+			"0|0        |0|0  |2021|2021|2021|2021|2021|2021",
+		},
+	},
+	{
+		name: "after fall back",
+		when: "time.Unix(1636266600, 0).In(newYork)",
+		want: []string{
+			"Sun|Sunday|Nov|November|Sun Nov  7 01:30:00 2021|20|07|11/07/21| 7|2021-11-07|21|2021|Nov|01|01|311| 1| 1|11|30|\n|000000000|AM|am|01:30:00 AM|01:30|1636266600|00|\t|01:30:00|7|45|44|0|44|11/07/21|01:30:00|21|2021|-0500|EST|%",
+			"Sun Nov  7 01:30:00 AM EST 2021|-05:00|1|000|000000|0000000000|0           |0",
+			"1| 1|1|01|AM|am|am|311|21|SUN|SUNDAY|NOVEMBER|est|001636266600|1|30|000000Sun Nov  7 01:30:00 2021|EST",
+			"EST|EST|est|-0500|-05:00|-05:00:00|-05",
+			"      2021|0000002021|2021|2021|2021|20|20|0020|002021|  2021|0001636266600|    1636266600",
+			"-500| -500|-5:00| -5:00|-5:00:00| -5:00:00|-5| -5|-000000500|      -500|-000005:00|    -5:00:00|-000000005|-0005:00|-5:00|-05:00:00|      -5|-0000005|-5:00",
+			"0|0        |0|0  |2021|2021|2021|2021|2021|2021",
+		},
+	},
+	{
+		name: "half hour offset",
+		when: "time.Unix(1136223245, 123456789).In(stJohns)",
+		want: []string{
+			"Mon|Monday|Jan|January|Mon Jan  2 14:04:05 2006|20|02|01/02/06| 2|2006-01-02|06|2006|Jan|14|02|002|14| 2|01|04|\n|123456789|PM|pm|02:04:05 PM|14:04|1136223245|05|\t|14:04:05|1|01|01|1|01|01/02/06|14:04:05|06|2006|-0330|NST|%",
+			"Mon Jan  2 14:04:05 PM NST 2006|-03:30|2|123|123456|1234567890|123456789   |123456789",
+			"2| 2|2|02|PM|pm|pm|2| 6|MON|MONDAY|JANUARY|nst|001136223245|14| 4|000000Mon Jan  2 14:04:05 2006|NST",
+			"NST|NST|nst|-0330|-03:30|-03:30:00|-03:30",
+			"      2006|0000002006|2006|2006|2006|20|20|0020|002006|  2006|0001136223245|    1136223245",
+			"-330| -330|-3:30| -3:30|-3:30:00| -3:30:00|-3:30| -3:30|-000000330|      -330|-000003:30|    -3:30:00|-000003:30|-0003:30|-3:30|-03:30:00|   -3:30|-0003:30|-3:30",
+			"123456789|123456789|123|123|2006|2006|2006|2006|2006|2006",
+		},
+	},
+	{
+		name: "local mean time",
+		when: "time.Date(1600, time.January, 1, 0, 0, 0, 0, newYork)",
+		want: []string{
+			"Sat|Saturday|Jan|January|Sat Jan  1 00:00:00 1600|16|01|01/01/00| 1|1600-01-01|99|1599|Jan|00|12|001| 0|12|01|00|\n|000000000|AM|am|12:00:00 AM|00:00|-11676078238|00|\t|00:00:00|6|00|52|6|00|01/01/00|00:00:00|00|1600|-0456|LMT|%",
+			"Sat Jan  1 00:00:00 AM LMT 1600|-04:56|12|000|000000|0000000000|0           |0",
+			"12|12|12|12|AM|am|am|1| 0|SAT|SATURDAY|JANUARY|lmt|-11676078238|0| 0|000000Sat Jan  1 00:00:00 1600|LMT",
+			"LMT|LMT|lmt|-0456|-04:56|-04:56:02|-04:56:02",
+			"      1600|0000001600|1600|1600|1600|16|16|0016|001599|  1599|-011676078238|  -11676078238",
+			"-456| -456|-4:56| -4:56|-4:56:02| -4:56:02|-4:56:02| -4:56:02|-000000456|      -456|-000004:56|    -4:56:02|-004:56:02|-0004:56|-4:56|-04:56:02|-4:56:02|-4:56:02|-4:56",
+			"0|0        |0|0  |1600|1600|1600|1600|1599|1599",
+		},
+	},
+	{
+		name: "long abbreviation",
+		when: "time.Unix(1152000000, 0).In(lordHowe)",
+		want: []string{
+			"Tue|Tuesday|Jul|July|Tue Jul  4 18:30:00 2006|20|04|07/04/06| 4|2006-07-04|06|2006|Jul|18|06|185|18| 6|07|30|\n|000000000|PM|pm|06:30:00 PM|18:30|1152000000|00|\t|18:30:00|2|27|27|2|27|07/04/06|18:30:00|06|2006|+1030|+1030|%",
+			"Tue Jul  4 18:30:00 PM +1030 2006|+10:30|6|000|000000|0000000000|0           |0",
+			"6| 6|6|06|PM|pm|pm|185| 6|TUE|TUESDAY|JULY|+1030|001152000000|18|30|000000Tue Jul  4 18:30:00 2006|+1030",
+			"+1030|+1030|+1030|+1030|+10:30|+10:30:00|+10:30",
+			"      2006|0000002006|2006|2006|2006|20|20|0020|002006|  2006|0001152000000|    1152000000",
+			"+1030|+1030|+10:30|+10:30|+10:30:00|+10:30:00|+10:30|+10:30|+000001030|     +1030|+000010:30|   +10:30:00|+000010:30|+0010:30|+10:30|+10:30:00|  +10:30|+0010:30|+10:30",
+			"0|0        |0|0  |2006|2006|2006|2006|2006|2006",
+		},
+	},
+}
+
+// conformanceModes lists the configurations under which every spec is
+// generated.
+var conformanceModes = []struct {
+	name   string
+	config Config
+}{
+	{"copy", Config{}},
+	{"append", Config{UseAppend: true}},
+	{"writer", Config{UseWriter: true}},
+	{"pairs", Config{DigitPairs: true}},
+	{"appendPairs", Config{UseAppend: true, DigitPairs: true}},
+}
+
+// conformanceLocations is the code that loads the locations that the
+// instants of the conformance tests may refer to.
+const conformanceLocations = `	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		panic(err)
+	}
+	stJohns, err := time.LoadLocation("America/St_Johns")
+	if err != nil {
+		panic(err)
+	}
+	lordHowe, err := time.LoadLocation("Australia/Lord_Howe")
+	if err != nil {
+		panic(err)
+	}
+	_, _, _ = newYork, stJohns, lordHowe
+`
+
+// TestConformance generates a function for every combination of spec and
+// mode, then runs a program that formats every instant with each of them.
+func TestConformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping conformance suite in short mode")
+	}
+	files := make(map[string][]byte)
+	var functions []string
+
+	for i, spec := range conformanceSpecs {
+		for _, mode := range conformanceModes {
+			config := mode.config
+			config.FuncName = fmt.Sprintf("%s%d", mode.name, i)
+			config.AllowExtra = true
+
+			cg, err := NewCodeGenerator(spec, &config)
+			if err != nil {
+				t.Fatalf("%s %q: %s", mode.name, spec, err)
+			}
+			files[config.FuncName+".go"] = cg.Bytes()
+
+			if config.UseWriter {
+				functions = append(functions, fmt.Sprintf("viaWriter(%s)", config.FuncName))
+			} else {
+				functions = append(functions, config.FuncName)
+			}
+		}
+	}
+
+	main := new(bytes.Buffer)
+	main.WriteString(`package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"time"
+	_ "time/tzdata"
+)
+
+func viaWriter(f func(*bufio.Writer, time.Time) error) func([]byte, time.Time) []byte {
+	return func(buf []byte, t time.Time) []byte {
+		bb := bytes.NewBuffer(buf)
+		w := bufio.NewWriter(bb)
+		if err := f(w, t); err != nil {
+			panic(err)
+		}
+		if err := w.Flush(); err != nil {
+			panic(err)
+		}
+		return bb.Bytes()
+	}
+}
+
+func main() {
+` + conformanceLocations + `
+	functions := []func([]byte, time.Time) []byte{
+`)
+	for _, f := range functions {
+		fmt.Fprintf(main, "\t\t%s,\n", f)
+	}
+	main.WriteString("\t}\n\n\tfor _, t := range []time.Time{\n")
+	for _, c := range conformanceCases {
+		fmt.Fprintf(main, "\t\t%s,\n", c.when)
+	}
+	main.WriteString(`	} {
+		for _, f := range functions {
+			fmt.Printf("%q\n", f(nil, t))
+		}
+	}
+}
+`)
+	files["main.go"] = main.Bytes()
+	output := runProgram(t, files)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for _, c := range conformanceCases {
+		for i, spec := range conformanceSpecs {
+			for _, mode := range conformanceModes {
+				if !scanner.Scan() {
+					t.Fatalf("%s %s %q: missing output", c.name, mode.name, spec)
+				}
+				got, err := strconv.Unquote(scanner.Text())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := c.want[i]; got != want {
+					t.Errorf("%s %s %q: GOT: %q; WANT: %q", c.name, mode.name, spec, got, want)
+				}
+			}
+		}
+	}
+	if scanner.Scan() {
+		t.Errorf("GOT: %q; WANT: no more output", scanner.Text())
+	}
+}
+
+// cacheSpecs are formatted by the caching formatter types emitted for them.
+var cacheSpecs = []string{
+	"%F %T.%3 %Z %z",
+	"%s|%N|%-I:%M:%S %p|%12N|%:z",
+}
+
+// cacheCases lists instants, as Go expressions, in the order that a single
+// caching formatter formats them, along with the text that each of
+// cacheSpecs must produce for them. Between them, the second, the minute, the
+// location, and the zone offset change, and the second goes back to one that
+// was formatted before. Expected values were produced by GNU date.
+var cacheCases = []struct {
+	name string
+	when string
+	want []string
+}{
+	{
+		name: "first second",
+		when: "time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)",
+		want: []string{"2006-01-02 15:04:05.123 UTC +0000", "1136214245|123456789|3:04:05 PM|123456789000|+00:00"},
+	},
+	{
+		name: "same second",
+		when: "time.Date(2006, time.January, 2, 15, 4, 5, 987654321, time.UTC)",
+		want: []string{"2006-01-02 15:04:05.987 UTC +0000", "1136214245|987654321|3:04:05 PM|987654321000|+00:00"},
+	},
+	{
+		name: "next second",
+		when: "time.Date(2006, time.January, 2, 15, 4, 6, 1, time.UTC)",
+		want: []string{"2006-01-02 15:04:06.000 UTC +0000", "1136214246|000000001|3:04:06 PM|000000001000|+00:00"},
+	},
+	{
+		name: "next minute",
+		when: "time.Date(2006, time.January, 2, 15, 5, 6, 500000000, time.UTC)",
+		want: []string{"2006-01-02 15:05:06.500 UTC +0000", "1136214306|500000000|3:05:06 PM|500000000000|+00:00"},
+	},
+	{
+		name: "same second in another location",
+		when: "time.Date(2006, time.January, 2, 15, 5, 6, 500000000, time.UTC).In(newYork)",
+		want: []string{"2006-01-02 10:05:06.500 EST -0500", "1136214306|500000000|10:05:06 AM|500000000000|-05:00"},
+	},
+	{
+		name: "before fall back",
+		when: "time.Unix(1636263000, 250000000).In(newYork)",
+		want: []string{"2021-11-07 01:30:00.250 EDT -0400", "1636263000|250000000|1:30:00 AM|250000000000|-04:00"},
+	},
+	{
+		name: "after fall back",
+		when: "time.Unix(1636266600, 250000000).In(newYork)",
+		want: []string{"2021-11-07 01:30:00.250 EST -0500", "1636266600|250000000|1:30:00 AM|250000000000|-05:00"},
+	},
+	{
+		name: "earlier second",
+		when: "time.Date(2006, time.January, 2, 15, 4, 5, 1000000, time.UTC)",
+		want: []string{"2006-01-02 15:04:05.001 UTC +0000", "1136214245|001000000|3:04:05 PM|001000000000|+00:00"},
+	},
+	{
+		name: "same second in another zone",
+		when: "time.Date(2006, time.January, 2, 15, 4, 5, 1000000, time.UTC).In(stJohns)",
+		want: []string{"2006-01-02 11:34:05.001 NST -0330", "1136214245|001000000|11:34:05 AM|001000000000|-03:30"},
+	},
+}
+
+// TestConformanceCache generates a function and a caching formatter type,
+// both with and without sync, for every spec, then runs a program that
+// formats every instant in turn with the same formatter, and with the
+// function that does not cache.
+func TestConformanceCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping conformance suite in short mode")
+	}
+	files := make(map[string][]byte)
+	var formatters []string
+
+	for i, spec := range cacheSpecs {
+		for _, sync := range []bool{false, true} {
+			config := Config{
+				FuncName:   fmt.Sprintf("format%d", len(formatters)),
+				CacheType:  fmt.Sprintf("cache%d", len(formatters)),
+				CacheSync:  sync,
+				AllowExtra: true,
+			}
+			cg, err := NewCodeGenerator(spec, &config)
+			if err != nil {
+				t.Fatalf("%d %q: %s", i, spec, err)
+			}
+			files[config.FuncName+".go"] = cg.Bytes()
+			formatters = append(formatters, config.CacheType)
+		}
+	}
+
+	main := new(bytes.Buffer)
+	main.WriteString(`package main
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+)
+
+func main() {
+` + conformanceLocations + "\n")
+	for i, name := range formatters {
+		fmt.Fprintf(main, "\tvar f%d %s\n", i, name)
+	}
+	main.WriteString("\n\tfor _, t := range []time.Time{\n")
+	for _, c := range cacheCases {
+		fmt.Fprintf(main, "\t\t%s,\n", c.when)
+	}
+	main.WriteString("\t} {\n")
+	for i := range formatters {
+		fmt.Fprintf(main, "\t\tfmt.Printf(\"%%q\\n%%q\\n\", f%d.AppendFormat(nil, t), format%d(nil, t))\n", i, i)
+	}
+	main.WriteString("\t}\n}\n")
+	files["main.go"] = main.Bytes()
+	output := runProgram(t, files)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for _, c := range cacheCases {
+		for i, name := range formatters {
+			want := c.want[i/2]
+			for _, via := range []string{name, "uncached"} {
+				if !scanner.Scan() {
+					t.Fatalf("%s %s: missing output", c.name, via)
+				}
+				got, err := strconv.Unquote(scanner.Text())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != want {
+					t.Errorf("%s %s: GOT: %q; WANT: %q", c.name, via, got, want)
+				}
+			}
+		}
+	}
+	if scanner.Scan() {
+		t.Errorf("GOT: %q; WANT: no more output", scanner.Text())
+	}
+}
+
+// runProgram writes files to a new module in a temporary directory, runs its
+// main package with the go tool, and returns what it printed. It skips the
+// test when the go tool cannot be found.
+func runProgram(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("cannot find go tool")
+	}
+
+	dir := t.TempDir()
+	files["go.mod"] = []byte("module generated\n\ngo 1.18\n")
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goTool, "run", ".")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s\n%s", err, output)
+	}
+	return output
+}
@@ -0,0 +1,184 @@
+package sftgen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+)
+
+// durationUnits ranks the formatting verbs that write a unit of a duration.
+// The largest unit in a spec is written in full, and every smaller unit is
+// written as what remains after the larger units.
+var durationUnits = map[rune]int{
+	'S': 1, // seconds
+	'M': 2, // minutes
+	'H': 3, // hours
+	'd': 4, // days
+}
+
+// checkDuration returns an error when config requests output that is only
+// emitted for times.
+func checkDuration(config *Config) error {
+	switch {
+	case config.Layout:
+		return errors.New("cannot use Go reference layouts with durations")
+	case config.ParseFuncName != "":
+		return errors.New("cannot emit parse function for durations")
+	case config.CacheType != "":
+		return errors.New("cannot use cache with durations")
+	}
+	return nil
+}
+
+// scanDuration builds the operations required to format a time.Duration
+// according to the directives.
+func (cg *CodeGenerator) scanDuration() (code, error) {
+	var dest code
+
+	var largest int
+	var signed bool // whether the spec writes the sign with %+
+	for _, d := range cg.directives {
+		if unit := durationUnits[d.verb]; unit > largest {
+			largest = unit
+		}
+		if d.verb == '+' {
+			signed = true
+		}
+	}
+
+	for _, d := range mergeConstants(cg.directives) {
+		if d.verb == 0 {
+			dest = join(dest, cg.writeStringConstant(d.literal))
+			continue
+		}
+
+		// Without %+, a negative duration is written with a minus sign
+		// before its first number.
+		if !signed && (durationUnits[d.verb] > 0 || d.fractionDigits() > 0) {
+			dest = join(dest, cg.writeDurationMinus())
+			signed = true
+		}
+
+		fill := d.fieldFill()
+		if fill != 0 {
+			dest = join(dest, cg.writeFieldStart())
+		}
+
+		var field code
+
+		switch d.verb {
+		case 'd':
+			field = cg.writeDurationUnit(d.verb, largest, d.padding('0'), d.fieldWidth(1))
+		case 'H', 'M', 'S':
+			field = cg.writeDurationUnit(d.verb, largest, d.padding('0'), d.fieldWidth(2))
+		case 'N', '3', '4':
+			field = cg.writeFractionField(d, cg.writeDurationFraction)
+		case 'n':
+			field = cg.writeN()
+		case 't':
+			field = cg.writeT()
+		case '%':
+			field = cg.writePercent()
+		case '+':
+			field = cg.writeDurationSign()
+		default:
+			return nil, fmt.Errorf("cannot recognize format verb %q at index %d for durations", d.verb, d.index)
+		}
+
+		if fill != 0 {
+			field = cg.writeFieldEnd(field, d.width, fill, false)
+		}
+		dest = join(dest, field)
+	}
+
+	return dest, nil
+}
+
+// durationSeconds returns the symbol of the number of whole seconds in the
+// magnitude of the duration, as a uint64, so that the magnitude of the
+// minimum duration does not overflow.
+func (cg *CodeGenerator) durationSeconds() string {
+	magnitude := cg.gensym(1, 1, durationMagnitude())
+	return cg.gensym(1, 1, binary(ident(magnitude), token.QUO, intLit(1000000000)))
+}
+
+// durationMagnitude returns the magnitude of the duration d, computed
+// without branches from the mask of its sign bit.
+func durationMagnitude() ast.Expr {
+	mask := func() ast.Expr {
+		return call("uint64", binary(ident("d"), token.SHR, intLit(63)))
+	}
+	return binary(paren(binary(call("uint64", ident("d")), token.XOR, mask())), token.SUB, mask())
+}
+
+// writeDurationUnit writes the number of days, hours, minutes, or seconds in
+// the duration, selected by verb. When verb is the largest unit in the spec,
+// its value is not limited, and is written with as many digits as it
+// requires; otherwise it is what remains after the larger units.
+func (cg *CodeGenerator) writeDurationUnit(verb rune, largest int, pad byte, width int) code {
+	seconds := cg.durationSeconds()
+
+	var per, limit int
+	switch verb {
+	case 'd':
+		per = 86400
+	case 'H':
+		per, limit = 3600, 24
+	case 'M':
+		per, limit = 60, 60
+	case 'S':
+		per, limit = 1, 60
+	}
+
+	var value ast.Expr = ident(seconds)
+	if per > 1 {
+		value = binary(value, token.QUO, intLit(per))
+	}
+	if durationUnits[verb] == largest {
+		units := cg.gensym(1, 1, call("int", value))
+		return join(section("writeDurationUnit %c", verb), cg.writeYearDigits(units, units, width, pad, false))
+	}
+	units := cg.gensym(1, 1, call("int", binary(value, token.REM, intLit(limit))))
+	return join(section("writeDurationUnit %c", verb), cg.writeDigits(units, width, pad))
+}
+
+// writeDurationFraction writes the fractional second of the duration
+// truncated to width digits.
+func (cg *CodeGenerator) writeDurationFraction(width int) code {
+	magnitude := cg.gensym(1, 1, durationMagnitude())
+	nanos := cg.gensym(1, 1, call("int", binary(ident(magnitude), token.REM, intLit(1000000000))))
+	if width < 9 {
+		divisor := 1
+		for i := width; i < 9; i++ {
+			divisor *= 10
+		}
+		nanos = cg.gensym(1, 1, binary(ident(nanos), token.QUO, intLit(divisor)))
+	}
+	return join(section("writeDurationFraction"), cg.writeDigitsZero(nanos, width))
+}
+
+// writeDurationSign writes '-' when the duration is negative, and '+'
+// otherwise.
+func (cg *CodeGenerator) writeDurationSign() code {
+	cg.maxLength++
+	negative := cg.gensym(1, 1, binary(call("uint64", ident("d")), token.SHR, intLit(63)))
+	sign := indexExpr(strLit("+-"), ident(negative))
+
+	if cg.useAppend {
+		return join(note("writeDurationSign append"), appendBytes(sign))
+	}
+	if cg.offset >= 0 {
+		cg.offset++
+		return join(note("writeDurationSign codegen offset"), assign(bufAt(intLit(cg.offset-1)), token.ASSIGN, sign))
+	}
+	return join(note("writeDurationSign runtime offset"), cg.writeNext(sign))
+}
+
+// writeDurationMinus writes '-' when the duration is negative.
+func (cg *CodeGenerator) writeDurationMinus() code {
+	cg.maxLength++
+
+	off := cg.useRuntimeOffset()
+	return join(off, section("writeDurationMinus"), ifElse(binary(ident("d"), token.LSS, intLit(0)), cg.writeByte('-'), nil))
+}
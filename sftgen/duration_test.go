@@ -0,0 +1,206 @@
+package sftgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestNewCodeGeneratorDuration(t *testing.T) {
+	cg, err := NewCodeGenerator("%H:%M:%S.%3", &Config{Duration: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"func formatDuration(buf []byte, d time.Duration) []byte {\n",
+		"if d < 0 {\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	cg, err = NewCodeGenerator("%+%dd", &Config{Duration: true, UseWriter: true, FuncName: "writeDays"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = cg.String()
+	for _, want := range []string{
+		"func writeDays(w *bufio.Writer, d time.Duration) error {\n",
+		"\"+-\"[",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+	if strings.Contains(got, "if d < 0 {") {
+		t.Errorf("GOT: %q; WANT: no implicit minus sign", got)
+	}
+
+	for _, c := range []struct {
+		spec   string
+		config Config
+		want   string
+	}{
+		{"%Y", Config{Duration: true}, `cannot recognize format verb 'Y' at index 1 for durations`},
+		{"15:04", Config{Duration: true, Layout: true}, "cannot use Go reference layouts with durations"},
+		{"%S", Config{Duration: true, ParseFuncName: "parseDuration"}, "cannot emit parse function for durations"},
+		{"%S", Config{Duration: true, CacheType: "DurationFormatter"}, "cannot use cache with durations"},
+	} {
+		config := c.config
+		_, err = NewCodeGenerator(c.spec, &config)
+		if got, want := err, c.want; got == nil || got.Error() != want {
+			t.Errorf("GOT: %v; WANT: %q", got, want)
+		}
+	}
+}
+
+// durationSpecs together use every duration verb, along with flags and field
+// widths.
+var durationSpecs = []string{
+	"%H:%M:%S.%N",
+	"%dd %H:%M:%S.%3",
+	"%+%S.%-N|%_3N|%4",
+	"%_H|%-M|%010S|%M%n%t%%",
+}
+
+// durationCases lists durations, as Go expressions, along with the text that
+// each of durationSpecs must produce for them. The largest unit of a spec is
+// written in full, and a negative duration is written with a minus sign
+// before its first number, unless the spec writes its sign with %+.
+var durationCases = []struct {
+	name string
+	when string
+	want []string
+}{
+	{
+		name: "zero",
+		when: "0",
+		want: []string{"00:00:00.000000000", "0d 00:00:00.000", "+00.0|0  |000000", " 0|0|0000000000|00\n\t%"},
+	},
+	{
+		name: "negative",
+		when: "-(90*time.Minute + 5*time.Second + 250*time.Millisecond)",
+		want: []string{"-01:30:05.250000000", "-0d 01:30:05.250", "-5405.25|25 |250000", "- 1|30|0000000005|30\n\t%"},
+	},
+	{
+		name: "sub-second",
+		when: "123456789",
+		want: []string{"00:00:00.123456789", "0d 00:00:00.123", "+00.123456789|123|123456", " 0|0|0000000000|00\n\t%"},
+	},
+	{
+		name: "negative sub-second",
+		when: "-500 * time.Millisecond",
+		want: []string{"-00:00:00.500000000", "-0d 00:00:00.500", "-00.5|5  |500000", "- 0|0|0000000000|00\n\t%"},
+	},
+	{
+		name: "over 24 hours",
+		when: "50*time.Hour + 3*time.Minute + 4*time.Second + 5",
+		want: []string{"50:03:04.000000005", "2d 02:03:04.000", "+180184.000000005|0  |000000", "50|3|0000000004|03\n\t%"},
+	},
+	{
+		name: "minimum",
+		when: "-1 << 63",
+		want: []string{"-2562047:47:16.854775808", "-106751d 23:47:16.854", "-9223372036.854775808|854|854775", "-2562047|47|0000000016|47\n\t%"},
+	},
+}
+
+// TestConformanceDuration generates a duration function for every
+// combination of spec and mode, then runs a program that formats every
+// duration with each of them.
+func TestConformanceDuration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping conformance suite in short mode")
+	}
+	files := make(map[string][]byte)
+	var functions []string
+
+	for i, spec := range durationSpecs {
+		for _, mode := range conformanceModes {
+			config := mode.config
+			config.FuncName = fmt.Sprintf("%s%d", mode.name, i)
+			config.Duration = true
+
+			cg, err := NewCodeGenerator(spec, &config)
+			if err != nil {
+				t.Fatalf("%s %q: %s", mode.name, spec, err)
+			}
+			files[config.FuncName+".go"] = cg.Bytes()
+
+			if config.UseWriter {
+				functions = append(functions, fmt.Sprintf("viaWriter(%s)", config.FuncName))
+			} else {
+				functions = append(functions, config.FuncName)
+			}
+		}
+	}
+
+	main := new(bytes.Buffer)
+	main.WriteString(`package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"time"
+)
+
+func viaWriter(f func(*bufio.Writer, time.Duration) error) func([]byte, time.Duration) []byte {
+	return func(buf []byte, d time.Duration) []byte {
+		bb := bytes.NewBuffer(buf)
+		w := bufio.NewWriter(bb)
+		if err := f(w, d); err != nil {
+			panic(err)
+		}
+		if err := w.Flush(); err != nil {
+			panic(err)
+		}
+		return bb.Bytes()
+	}
+}
+
+func main() {
+	functions := []func([]byte, time.Duration) []byte{
+`)
+	for _, f := range functions {
+		fmt.Fprintf(main, "\t\t%s,\n", f)
+	}
+	main.WriteString("\t}\n\n\tfor _, d := range []time.Duration{\n")
+	for _, c := range durationCases {
+		fmt.Fprintf(main, "\t\t%s,\n", c.when)
+	}
+	main.WriteString(`	} {
+		for _, f := range functions {
+			fmt.Printf("%q\n", f(nil, d))
+		}
+	}
+}
+`)
+	files["main.go"] = main.Bytes()
+	output := runProgram(t, files)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for _, c := range durationCases {
+		for i, spec := range durationSpecs {
+			for _, mode := range conformanceModes {
+				if !scanner.Scan() {
+					t.Fatalf("%s %s %q: missing output", c.name, mode.name, spec)
+				}
+				got, err := strconv.Unquote(scanner.Text())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want := c.want[i]; got != want {
+					t.Errorf("%s %s %q: GOT: %q; WANT: %q", c.name, mode.name, spec, got, want)
+				}
+			}
+		}
+	}
+	if scanner.Scan() {
+		t.Errorf("GOT: %q; WANT: no more output", scanner.Text())
+	}
+}
@@ -0,0 +1,101 @@
+package sftgen
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"strconv"
+)
+
+// checkFormatterType returns an error when the formatter type named by
+// config cannot be emitted.
+func checkFormatterType(config *Config) error {
+	switch {
+	case !token.IsIdentifier(config.FormatterType):
+		return fmt.Errorf("cannot use %q as type name", config.FormatterType)
+	case config.FormatterType == config.CacheType:
+		return fmt.Errorf("cannot use %q as name of both cache and formatter types", config.FormatterType)
+	case config.UseWriter:
+		return errors.New("cannot use formatter type with writer")
+	}
+	return nil
+}
+
+// formatterSource returns the source code of the formatter type and its
+// methods, which format values with the emitted function.
+func (cg *CodeGenerator) formatterSource() []byte {
+	name := cg.formatterType
+	parameter, what := "t time.Time", "times"
+	if cg.duration {
+		parameter, what = "d time.Duration", "durations"
+	}
+	argument := parameter[:1]
+
+	// Without a maximum length, the buffers have room for zone names of up
+	// to zoneRoom bytes, and the function allocates for longer ones.
+	const zoneRoom = 32
+	size := fmt.Sprintf("%sMaxLen", cg.functionName)
+	if cg.MaxLength() < 0 {
+		size = strconv.Itoa(cg.maxLength + zoneRoom)
+	}
+
+	dest := make([]byte, 0, 2048)
+
+	appendString(&dest, `// %s formats %s according to %q, with %s. Its zero
+// value is ready to use, and it is safe for concurrent use.
+type %s struct{}
+
+// AppendFormat appends %s formatted according to %q to buf.
+func (%s) AppendFormat(buf []byte, %s) []byte {
+`, name, what, cg.spec, cg.functionName, name, argument, cg.spec, name, parameter)
+
+	if cg.useAppend && !cg.truncate {
+		appendString(&dest, "    return %s(buf, %s)\n}\n\n", cg.functionName, argument)
+	} else {
+		// The function writes from the start of its byte slice, so it is
+		// given a scratch buffer, and what it wrote is appended to buf.
+		scratch := "scratch[:]"
+		if cg.useAppend {
+			scratch = "scratch[:0]"
+		}
+		appendString(&dest, `    var scratch [%s]byte
+    return append(buf, %s(%s, %s)...)
+}
+
+`, size, cg.functionName, scratch, argument)
+	}
+
+	appendString(&dest, `// Format returns %s formatted according to %q.
+func (f %s) Format(%s) string {
+    var buf [%s]byte
+    return string(f.AppendFormat(buf[:0], %s))
+}
+
+`, argument, cg.spec, name, parameter, size, argument)
+
+	if cg.MaxLength() < 0 {
+		appendString(&dest, `// MaxLen returns -1, because the formatted text includes zone names, which
+// have no maximum length.
+func (%s) MaxLen() int {
+    return -1
+}
+
+`, name)
+	} else {
+		appendString(&dest, `// MaxLen returns the maximum length of the formatted text.
+func (%s) MaxLen() int {
+    return %sMaxLen
+}
+
+`, name, cg.functionName)
+	}
+
+	appendString(&dest, `// Spec returns the spec that the text is formatted according to.
+func (%s) Spec() string {
+    return %q
+}
+
+`, name, cg.spec)
+
+	return dest
+}
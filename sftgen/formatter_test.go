@@ -0,0 +1,105 @@
+package sftgen
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestNewCodeGeneratorFormatterType(t *testing.T) {
+	cg, err := NewCodeGenerator("%F %T", &Config{FormatterType: "AccessLogTime"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"// AccessLogTime formats times according to \"%F %T\", with formatTime. Its zero\n// value is ready to use, and it is safe for concurrent use.\ntype AccessLogTime struct{}\n",
+		"// AppendFormat appends t formatted according to \"%F %T\" to buf.\nfunc (AccessLogTime) AppendFormat(",
+		"// Format returns t formatted according to \"%F %T\".\nfunc (f AccessLogTime) Format(",
+		"// MaxLen returns the maximum length of the formatted text.\nfunc (AccessLogTime) MaxLen() int {\n",
+		"// Spec returns the spec that the text is formatted according to.\nfunc (AccessLogTime) Spec() string {\n",
+		"type AccessLogTime struct{}\n",
+		"func (AccessLogTime) AppendFormat(buf []byte, t time.Time) []byte {\n",
+		"return append(buf, formatTime(scratch[:], t)...)\n",
+		"func (f AccessLogTime) Format(t time.Time) string {\n",
+		"func (AccessLogTime) MaxLen() int {\n\treturn formatTimeMaxLen\n}\n",
+		"func (AccessLogTime) Spec() string {\n\treturn \"%F %T\"\n}\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	cg, err = NewCodeGenerator("%H:%M", &Config{FormatterType: "clock", UseAppend: true, Duration: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := cg.String(), "func (clock) AppendFormat(buf []byte, d time.Duration) []byte {\n\treturn formatDuration(buf, d)\n}\n"; !strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	for _, c := range []struct {
+		config Config
+		want   string
+	}{
+		{Config{FormatterType: "access log"}, `cannot use "access log" as type name`},
+		{Config{FormatterType: "Stamp", CacheType: "Stamp"}, `cannot use "Stamp" as name of both cache and formatter types`},
+		{Config{FormatterType: "Stamp", UseWriter: true}, "cannot use formatter type with writer"},
+	} {
+		_, err = NewCodeGenerator("%F", &c.config)
+		if got := err; got == nil || got.Error() != c.want {
+			t.Errorf("GOT: %v; WANT: %q", got, c.want)
+		}
+	}
+}
+
+// TestFormatterTypeLongZone formats a time in zones with long names through
+// formatter types, whose buffers cannot be sized for every zone name.
+func TestFormatterTypeLongZone(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping formatter type program in short mode")
+	}
+
+	configs := []Config{
+		{FormatterType: "Copy", FuncName: "formatCopy"},
+		{FormatterType: "Append", FuncName: "formatAppend", UseAppend: true},
+		{FormatterType: "Truncate", FuncName: "formatTruncate", UseAppend: true, Truncate: true},
+		{FormatterType: "Location", FuncName: "formatLocation", ZoneStyle: ZoneLocation},
+	}
+	names := []string{
+		"ACWST",
+		"America/Argentina/ComodRivadavia/And/Then/Some",
+	}
+
+	files := make(map[string][]byte)
+	main := new(bytes.Buffer)
+	main.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"time\"\n)\n\nfunc main() {\n\tvar when time.Time\n")
+	for i := range configs {
+		config := &configs[i]
+		cg, err := NewCodeGenerator("%F %T %Z", config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[config.FuncName+".go"] = cg.Bytes()
+		for _, name := range names {
+			fmt.Fprintf(main, "\twhen = time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone(%q, -3*3600))\n", name)
+			fmt.Fprintf(main, "\tfmt.Println(%s{}.Format(when))\n", config.FormatterType)
+			fmt.Fprintf(main, "\tfmt.Println(string(%s{}.AppendFormat([]byte(\"prefix \"), when)))\n", config.FormatterType)
+		}
+	}
+	main.WriteString("}\n")
+	files["main.go"] = main.Bytes()
+	output := runProgram(t, files)
+
+	var want string
+	for range configs {
+		for _, name := range names {
+			want += "2006-01-02 15:04:05 " + name + "\n"
+			want += "prefix 2006-01-02 15:04:05 " + name + "\n"
+		}
+	}
+	if got := string(output); got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+}
@@ -0,0 +1,289 @@
+package sftgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/karrick/sft/internal/reference"
+)
+
+// prepareTest builds the source code of a test file with a fuzz target for
+// each of the generators when emitTest is true, and with benchmarks for each
+// of the generators when emitBench is true.
+func (cg *CodeGenerator) prepareTest(generators []*CodeGenerator) error {
+	libraries := map[string]struct{}{
+		"testing": {},
+		"time":    {},
+	}
+
+	var functions []byte
+
+	if cg.emitTest {
+		fuzz, err := fuzzTargets(generators, libraries)
+		if err != nil {
+			return err
+		}
+		functions = append(functions, fuzz...)
+	}
+	if cg.emitBench {
+		functions = append(functions, benchmarks(generators, libraries)...)
+	}
+
+	dest := make([]byte, 0, len(cg.header)+4096+len(functions))
+
+	appendString(&dest, "package %s\n\n", cg.packageName)
+
+	sortedLibraries := make([]string, 0, len(libraries))
+	for p := range libraries {
+		sortedLibraries = append(sortedLibraries, p)
+	}
+	sort.Strings(sortedLibraries)
+
+	appendString(&dest, "import (\n")
+	for _, p := range sortedLibraries {
+		appendString(&dest, "    \"%s\"\n", p)
+	}
+	appendString(&dest, ")\n\n")
+
+	dest = append(dest, functions...)
+
+	dest, err := gofmt(dest)
+	if err != nil {
+		return err
+	}
+	if lh := len(cg.header); lh > 0 {
+		header := make([]byte, 0, lh+len(dest))
+		header = append(header, cg.header...)
+		dest = append(header, dest...)
+	}
+	cg.testBuf = dest
+
+	return nil
+}
+
+// fuzzTargets returns the source code of a fuzz target for each of the
+// generators, adding the packages it requires to libraries. Every fuzz target
+// compares the output of its generated function with time.Format when its
+// spec is a Go reference layout, or with a reference strftime interpreter
+// otherwise, which also formats durations.
+func fuzzTargets(generators []*CodeGenerator, libraries map[string]struct{}) ([]byte, error) {
+	// All helper functions are named after the first function, so that test
+	// files generated for different functions may share a package.
+	prefix := generators[0].functionName
+
+	var functions []byte
+	var reference bool
+
+	for _, g := range generators {
+		// The reference interpreter always writes the zone abbreviation for
+		// %Z, so it formats a time whose zone abbreviation is what the zone
+		// style of the generated function writes.
+		var zoned string
+		at := "when"
+		if !g.layout && !g.duration {
+			switch g.zoneStyle {
+			case ZoneAbbreviationOrOffset:
+				zoned = fmt.Sprintf(`zoned := when
+        if name, seconds := when.Zone(); name == "" {
+            zoned = when.In(time.FixedZone(%sReferenceOffset(seconds, 0), seconds))
+        }
+        `, prefix)
+				at = "zoned"
+			case ZoneLocation:
+				zoned = `_, seconds := when.Zone()
+        zoned := when.In(time.FixedZone(when.Location().String(), seconds))
+        `
+				at = "zoned"
+			}
+		}
+
+		var want string
+		switch {
+		case g.layout:
+			want = fmt.Sprintf("when.Format(%q)", g.spec)
+		case g.duration:
+			want = fmt.Sprintf("string(%sReferenceFormatDuration(nil, %q, when))", prefix, g.spec)
+			reference = true
+		default:
+			want = fmt.Sprintf("string(%sReferenceFormat(nil, %q, %s))", prefix, g.spec, at)
+			if !g.locale.isEnglish() {
+				l := g.locale
+				want = fmt.Sprintf(`string(%sReferenceFormatLocale(nil, %q, %s, &%sReferenceLocale{
+            weekdaysLong: %#v,
+            weekdaysShort: %#v,
+            monthsLong: %#v,
+            monthsShort: %#v,
+            am: %q,
+            pm: %q,
+        }))`, prefix, g.spec, at, prefix, l.WeekdaysLong, l.WeekdaysShort, l.MonthsLong, l.MonthsShort, l.AM, l.PM)
+			}
+			reference = true
+		}
+
+		// The cache is checked by the fuzz target of its function, so that
+		// every function has a single fuzz target named after it. The cached
+		// text must only be reused for times in the same second and
+		// location.
+		var cached string
+		if g.cache != nil {
+			cached = fmt.Sprintf(`
+        var formatter %s
+        for _, when := range []time.Time{
+            when,
+            time.Unix(when.Unix(), int64(when.Nanosecond()/7)).In(when.Location()),
+            when.Add(time.Second),
+            when.UTC(),
+        } {
+            got := string(formatter.AppendFormat([]byte("fuzz "), when))
+            %swant := "fuzz " + %s
+            if got != want {
+                t.Errorf("%%s: %s: GOT: %%q; WANT: %%q", when, got, want)
+            }
+        }`, g.cache.cacheType, zoned, want, g.cache.cacheType)
+		}
+
+		got := fmt.Sprintf("string(%s(nil, when))", g.functionName)
+		if g.useWriter {
+			// A function that writes must append after the bytes already
+			// buffered by the writer.
+			libraries["bufio"] = struct{}{}
+			libraries["bytes"] = struct{}{}
+			got = fmt.Sprintf(`func() string {
+            var bb bytes.Buffer
+            w := bufio.NewWriter(&bb)
+            _, _ = w.WriteString("fuzz ")
+            if err := %s(w, when); err != nil {
+                t.Fatal(err)
+            }
+            if err := w.Flush(); err != nil {
+                t.Fatal(err)
+            }
+            return bb.String()
+        }()`, g.functionName)
+			want = `"fuzz " + ` + want
+		} else if g.useAppend && !g.truncate {
+			// A function that appends must keep the existing contents of
+			// its byte slice.
+			got = fmt.Sprintf("string(%s([]byte(\"fuzz \"), when))", g.functionName)
+			want = `"fuzz " + ` + want
+		}
+
+		if g.duration {
+			appendString(&functions, `func Fuzz%s(f *testing.F) {
+    f.Add(int64(93784123456789))
+    f.Add(int64(-93784123456789))
+    f.Add(int64(math.MinInt64))
+    f.Fuzz(func(t *testing.T, nsec int64) {
+        when := time.Duration(nsec)
+        got := %s
+        want := %s
+        if got != want {
+            t.Errorf("%%s: GOT: %%q; WANT: %%q", when, got, want)
+        }
+    })
+}
+
+`, strings.ToUpper(g.functionName[:1])+g.functionName[1:], got, want)
+			libraries["math"] = struct{}{}
+			continue
+		}
+
+		appendString(&functions, `func Fuzz%s(f *testing.F) {
+    f.Add(int64(1136214245), int64(123456789), int32(0), uint8(0))
+    f.Add(int64(946684799), int64(999999999), int32(-25200), uint8(1))
+    f.Add(int64(951782400), int64(0), int32(19800), uint8(2))
+    f.Add(int64(0), int64(0), int32(-3600), uint8(1))
+    f.Add(int64(6310008217800), int64(0), int32(31500), uint8(5))
+    f.Add(int64(6324810672427), int64(1), int32(-10800), uint8(6))
+    f.Fuzz(func(t *testing.T, sec, nsec int64, offset int32, zone uint8) {
+        when := %sFuzzTime(sec, nsec, offset, zone)
+        got := %s
+        %swant := %s
+        if got != want {
+            t.Errorf("%%s: GOT: %%q; WANT: %%q", when, got, want)
+        }%s
+    })
+}
+
+`, strings.ToUpper(g.functionName[:1])+g.functionName[1:], prefix, got, zoned, want, cached)
+	}
+
+	// The seeds include times with negative years and seconds since the
+	// epoch, which are written with a minus sign within padded fields: with
+	// sec offset from min, 6310008217800 is -0044-03-15 12:30:00 UTC, and
+	// 6324810672427 is 0425-04-10 05:27:07 UTC. Zone five has an
+	// abbreviation, and zone six a location name, longer than three bytes.
+	appendString(&functions, `// %sFuzzTime maps the arguments of a fuzz target to a time between the years
+// -200000 and 200000, in a zone with an arbitrary offset and an abbreviation
+// or location name of up to 30 bytes.
+func %sFuzzTime(sec, nsec int64, offset int32, zone uint8) time.Time {
+    const min, max = -6373557532800, 6249254716799 // -200000-01-02 and 200000-12-30
+    sec = min + (sec%%(max-min)+(max-min))%%(max-min)
+    nsec = (nsec%%1000000000 + 1000000000) %% 1000000000
+    names := [...]string{"UTC", "", "EST", "CET", "XYZ", "ACWST", "America/Argentina/Buenos_Aires"}
+    loc := time.FixedZone(names[int(zone)%%len(names)], int(offset)%%(18*3600))
+    return time.Unix(sec, nsec).In(loc)
+}
+
+`, prefix, prefix)
+
+	if reference {
+		decls, imports, err := referenceDecls(prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range imports {
+			libraries[p] = struct{}{}
+		}
+		functions = append(functions, decls...)
+	}
+
+	return functions, nil
+}
+
+// referenceDecls returns the source code of the declarations of the
+// reference strftime interpreter, after renaming its identifiers to start
+// with prefix, along with the packages it imports.
+func referenceDecls(prefix string) ([]byte, []string, error) {
+	fs := token.NewFileSet()
+	f, err := parser.ParseFile(fs, "strftime.go", reference.Source, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && strings.HasPrefix(id.Name, "reference") {
+			id.Name = prefix + "R" + id.Name[1:]
+		}
+		return true
+	})
+
+	var imports []string
+	for _, spec := range f.Imports {
+		p, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		imports = append(imports, p)
+	}
+
+	bb := new(bytes.Buffer)
+	for _, decl := range f.Decls {
+		if gd, ok := decl.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			continue
+		}
+		if err := format.Node(bb, fs, decl); err != nil {
+			return nil, nil, err
+		}
+		bb.WriteString("\n\n")
+	}
+
+	return bb.Bytes(), imports, nil
+}
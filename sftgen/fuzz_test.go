@@ -0,0 +1,80 @@
+package sftgen
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNewCodeGeneratorEmitTest(t *testing.T) {
+	cg, err := NewCodeGenerator("%F %T", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cg.WriteTestTo(new(bytes.Buffer))
+	if got, want := err, "cannot write test without EmitTest or EmitBench option"; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+
+	cg, err = NewCodeGenerator("%F %T", &Config{EmitTest: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb := new(bytes.Buffer)
+	if _, err = cg.WriteTestTo(bb); err != nil {
+		t.Fatal(err)
+	}
+	got := bb.String()
+	for _, want := range []string{
+		"func FuzzFormatTime(f *testing.F) {\n",
+		"func formatTimeFuzzTime(sec, nsec int64, offset int32, zone uint8) time.Time {\n",
+		"func formatTimeReferenceFormat(buf []byte, spec string, t time.Time) []byte {\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func TestNewCodeGeneratorEmitTestLayout(t *testing.T) {
+	cg, err := NewCodeGenerator("2006-01-02T15:04:05Z07:00", &Config{EmitTest: true, Layout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb := new(bytes.Buffer)
+	if _, err = cg.WriteTestTo(bb); err != nil {
+		t.Fatal(err)
+	}
+	got := bb.String()
+	if want := `when.Format("2006-01-02T15:04:05Z07:00")`; !strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+	if want := "ReferenceFormat"; strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: no %q", got, want)
+	}
+}
+
+func TestNewCodeGeneratorEmitTestCache(t *testing.T) {
+	cg, err := NewCodeGenerator("%F %T.%3N", &Config{EmitTest: true, CacheType: "TimeFormatter"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bb := new(bytes.Buffer)
+	if _, err = cg.WriteTestTo(bb); err != nil {
+		t.Fatal(err)
+	}
+	got := bb.String()
+	// The cache is checked by the fuzz target of the function, so that
+	// `go test -fuzz Fuzz` matches a single fuzz target.
+	if got, want := strings.Count(got, "func Fuzz"), 1; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+	for _, want := range []string{
+		"func FuzzFormatTime(f *testing.F) {\n",
+		"var formatter TimeFormatter\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// This file was auto generated using the following command:
+//    ./sft -p fixedbench -f formatDateTime -o sftgen/internal/fixedbench/datetime.go "%F %T"
+
+package fixedbench
+
+import (
+	"strconv"
+	"time"
+)
+
+// formatDateTimeMaxLen is the maximum length of the text formatted by formatDateTime.
+const formatDateTimeMaxLen = 35
+
+func formatDateTime(buf []byte, t time.Time) []byte {
+	const digits = "0123456789 123456789"
+	var quotient, remainder int
+
+	if len(buf) < 35 {
+		buf = make([]byte, 35)
+	}
+
+	gs0, gs1, gs2 := t.Date()
+	gs3 := int(gs1)
+	gs4, gs5, gs6 := t.Clock()
+
+	if gs0 >= 0 && gs0 < 10000 {
+		_ = buf[18]
+
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[0] = digits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[1] = digits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[2] = digits[quotient]
+		buf[3] = digits[remainder]
+		buf[4] = '-'
+		quotient = gs3 / 10
+		remainder = gs3 % 10
+		buf[5] = digits[quotient]
+		buf[6] = digits[remainder]
+		buf[7] = '-'
+		quotient = gs2 / 10
+		remainder = gs2 % 10
+		buf[8] = digits[quotient]
+		buf[9] = digits[remainder]
+		buf[10] = ' '
+
+		quotient = gs4 / 10
+		remainder = gs4 % 10
+		buf[11] = digits[quotient]
+		buf[12] = digits[remainder]
+		buf[13] = ':'
+		quotient = gs5 / 10
+		remainder = gs5 % 10
+		buf[14] = digits[quotient]
+		buf[15] = digits[remainder]
+		buf[16] = ':'
+		quotient = gs6 / 10
+		remainder = gs6 % 10
+		buf[17] = digits[quotient]
+		buf[18] = digits[remainder]
+		return buf[:19]
+	}
+
+	offset := 0
+	if gs0 >= 0 && gs0 < 10000 {
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[offset] = digits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[offset+1] = digits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[offset+2] = digits[quotient]
+		buf[offset+3] = digits[remainder]
+		offset += 4
+	} else {
+		v := gs0
+		if v < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 4 {
+			offset = start + 4
+			copy(buf[start+4-n:], buf[start:start+n])
+			for i := start; i < start+4-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+	offset += copy(buf[offset:], "-")
+	quotient = gs3 / 10
+	remainder = gs3 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], "-")
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], " ")
+
+	quotient = gs4 / 10
+	remainder = gs4 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], ":")
+	quotient = gs5 / 10
+	remainder = gs5 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], ":")
+	quotient = gs6 / 10
+	remainder = gs6 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+
+	buf = buf[:offset]
+	return buf
+}
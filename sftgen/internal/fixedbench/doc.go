@@ -0,0 +1,10 @@
+// Package fixedbench measures the gain of formatting a fixed width spec in a
+// single bounds checked block. formatFixed is emitted by the current
+// generator; formatPerByte is what the generator emitted for the same spec
+// before that optimization, when every byte was stored with its own bounds
+// check, and is kept unchanged as the baseline. Likewise, formatDateTime
+// writes years from 0 through 9999 in such a block, and
+// formatDateTimeVariable is its baseline, which tracks the offset of every
+// byte at runtime because years vary in width. Regenerate formatFixed and
+// formatDateTime with "make fixedbench", which also runs the benchmarks.
+package fixedbench
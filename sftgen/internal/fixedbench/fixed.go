@@ -0,0 +1,71 @@
+// This file was auto generated using the following command:
+//    ./sft -extra -p fixedbench -f formatFixed -o sftgen/internal/fixedbench/fixed.go "%m/%d %T.%3 UTC"
+
+package fixedbench
+
+import (
+	"time"
+)
+
+// formatFixedMaxLen is the maximum length of the text formatted by formatFixed.
+const formatFixedMaxLen = 22
+
+// formatFixedFixedLen is the length of every text formatted by formatFixed.
+const formatFixedFixedLen = 22
+
+func formatFixed(buf []byte, t time.Time) []byte {
+	const digits = "0123456789 123456789"
+	var quotient, remainder int
+
+	if len(buf) < 22 {
+		buf = make([]byte, 22)
+	}
+
+	_ = buf[21]
+
+	_, gs0, gs2 := t.Date()
+	gs1 := int(gs0)
+	gs3, gs4, gs5 := t.Clock()
+	gs6 := t.Nanosecond()
+	gs7 := gs6 / 1000000
+
+	quotient = gs1 / 10
+	remainder = gs1 % 10
+	buf[0] = digits[quotient]
+	buf[1] = digits[remainder]
+	buf[2] = '/'
+
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[3] = digits[quotient]
+	buf[4] = digits[remainder]
+	buf[5] = ' '
+
+	quotient = gs3 / 10
+	remainder = gs3 % 10
+	buf[6] = digits[quotient]
+	buf[7] = digits[remainder]
+	buf[8] = ':'
+	quotient = gs4 / 10
+	remainder = gs4 % 10
+	buf[9] = digits[quotient]
+	buf[10] = digits[remainder]
+	buf[11] = ':'
+	quotient = gs5 / 10
+	remainder = gs5 % 10
+	buf[12] = digits[quotient]
+	buf[13] = digits[remainder]
+	buf[14] = '.'
+
+	quotient = gs7 / 100
+	remainder = gs7 % 100
+	buf[15] = digits[quotient]
+	quotient = remainder / 10
+	remainder %= 10
+	buf[16] = digits[quotient]
+	buf[17] = digits[remainder]
+	copy(buf[18:22], " UTC")
+
+	buf = buf[:22]
+	return buf
+}
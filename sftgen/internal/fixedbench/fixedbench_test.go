@@ -0,0 +1,70 @@
+package fixedbench
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatFixed(t *testing.T) {
+	for _, when := range []time.Time{
+		time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC),
+		time.Date(1999, time.December, 31, 23, 59, 59, 999999999, time.UTC),
+	} {
+		if got, want := string(formatFixed(nil, when)), string(formatPerByte(nil, when)); got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func BenchmarkFormatPerByte(b *testing.B) {
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)
+	buf := make([]byte, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = formatPerByte(buf, when)
+	}
+}
+
+func BenchmarkFormatFixed(b *testing.B) {
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)
+	buf := make([]byte, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = formatFixed(buf, when)
+	}
+}
+
+func TestFormatDateTime(t *testing.T) {
+	for _, when := range []time.Time{
+		time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC),
+		time.Date(0, time.June, 15, 8, 9, 10, 0, time.UTC),
+		time.Date(-44, time.March, 15, 12, 30, 0, 0, time.UTC),
+		time.Date(10000, time.January, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if got, want := string(formatDateTime(nil, when)), string(formatDateTimeVariable(nil, when)); got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
+
+func BenchmarkFormatDateTimeVariable(b *testing.B) {
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	buf := make([]byte, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = formatDateTimeVariable(buf, when)
+	}
+}
+
+func BenchmarkFormatDateTime(b *testing.B) {
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	buf := make([]byte, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = formatDateTime(buf, when)
+	}
+}
@@ -0,0 +1,89 @@
+// formatDateTimeVariable was emitted by the generator before years from 0
+// through 9999 were written in a single bounds checked block, using the
+// command:
+//    sft -p fixedbench -f formatDateTimeVariable -o variable.go "%F %T"
+
+package fixedbench
+
+import (
+	"strconv"
+	"time"
+)
+
+func formatDateTimeVariable(buf []byte, t time.Time) []byte {
+	const digits = "0123456789 123456789"
+	var quotient, remainder int
+
+	if len(buf) < 35 {
+		buf = make([]byte, 35)
+	}
+
+	gs0, gs1, gs2 := t.Date()
+	gs3 := int(gs1)
+	gs4, gs5, gs6 := t.Clock()
+
+	offset := 0
+	if gs0 >= 0 && gs0 < 10000 {
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[offset] = digits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[offset+1] = digits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[offset+2] = digits[quotient]
+		buf[offset+3] = digits[remainder]
+		offset += 4
+	} else {
+		v := gs0
+		if v < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 4 {
+			offset = start + 4
+			copy(buf[start+4-n:], buf[start:start+n])
+			for i := start; i < start+4-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+	offset += copy(buf[offset:], "-")
+	quotient = gs3 / 10
+	remainder = gs3 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], "-")
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], " ")
+
+	quotient = gs4 / 10
+	remainder = gs4 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], ":")
+	quotient = gs5 / 10
+	remainder = gs5 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], ":")
+	quotient = gs6 / 10
+	remainder = gs6 % 10
+	buf[offset] = digits[quotient]
+	buf[offset+1] = digits[remainder]
+	offset += 2
+
+	buf = buf[:offset]
+	return buf
+}
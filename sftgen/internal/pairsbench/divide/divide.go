@@ -0,0 +1,1544 @@
+// This file was auto generated using the following command:
+//    ../../../../sft -extra -p divide -manifest ../verbs.txt -o "divide.go"
+
+package divide
+
+import (
+	"strconv"
+	"time"
+)
+
+const sftAmpmc = "AMPM"
+
+var sftAmpmIndex = []int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}
+
+const sftDigits = "0123456789 123456789"
+const sftWeekdaysLong = "SundayMondayTuesdayWednesdayThursdayFridaySaturday"
+
+var sftWeekdaysLongIndices = []int{0, 6, 12, 19, 28, 36, 42, 50}
+
+const sftMonthsLong = "JanuaryFebruaryMarchAprilMayJuneJulyAugustSeptemberOctoberNovemberDecember"
+
+var sftMonthsLongIndices = []int{0, 7, 15, 20, 25, 28, 32, 36, 42, 51, 58, 66, 74}
+
+// CenturyMaxLen is the maximum length of the text formatted by Century.
+const CenturyMaxLen = 20
+
+func Century(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 20 {
+		buf = make([]byte, 20)
+	}
+
+	gs0, _, _ := t.Date()
+	gs1 := gs0 / 100
+
+	if gs0 >= 0 && gs1 < 100 {
+		_ = buf[1]
+
+		quotient = gs1 / 10
+		remainder = gs1 % 10
+		buf[0] = sftDigits[quotient]
+		buf[1] = sftDigits[remainder]
+		return buf[:2]
+	}
+
+	offset := 0
+	if gs0 >= 0 && gs1 < 100 {
+		quotient = gs1 / 10
+		remainder = gs1 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+	} else {
+		v := gs1
+		if gs0 < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 1 {
+			offset = start + 1
+			copy(buf[start+1-n:], buf[start:start+n])
+			for i := start; i < start+1-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+
+	buf = buf[:offset]
+	return buf
+}
+
+// DayMaxLen is the maximum length of the text formatted by Day.
+const DayMaxLen = 2
+
+// DayFixedLen is the length of every text formatted by Day.
+const DayFixedLen = 2
+
+func Day(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	_, _, gs0 := t.Date()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// DaySpaceMaxLen is the maximum length of the text formatted by DaySpace.
+const DaySpaceMaxLen = 2
+
+// DaySpaceFixedLen is the length of every text formatted by DaySpace.
+const DaySpaceFixedLen = 2
+
+func DaySpace(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	_, _, gs0 := t.Date()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[10+quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// DateMaxLen is the maximum length of the text formatted by Date.
+const DateMaxLen = 8
+
+// DateFixedLen is the length of every text formatted by Date.
+const DateFixedLen = 8
+
+func Date(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 8 {
+		buf = make([]byte, 8)
+	}
+
+	_ = buf[7]
+
+	gs3, gs0, gs2 := t.Date()
+	gs1 := int(gs0)
+	gs4 := gs3 % 100
+	gs5 := gs4 >> 63
+	gs6 := (gs4 ^ gs5) - gs5
+
+	quotient = gs1 / 10
+	remainder = gs1 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+	buf[2] = '/'
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[3] = sftDigits[quotient]
+	buf[4] = sftDigits[remainder]
+	buf[5] = '/'
+	quotient = gs6 / 10
+	remainder = gs6 % 10
+	buf[6] = sftDigits[quotient]
+	buf[7] = sftDigits[remainder]
+
+	buf = buf[:8]
+	return buf
+}
+
+// FullMaxLen is the maximum length of the text formatted by Full.
+const FullMaxLen = 26
+
+func Full(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 26 {
+		buf = make([]byte, 26)
+	}
+
+	gs0, gs1, gs2 := t.Date()
+	gs3 := int(gs1)
+
+	if gs0 >= 0 && gs0 < 10000 {
+		_ = buf[9]
+
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[0] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[2] = sftDigits[quotient]
+		buf[3] = sftDigits[remainder]
+		buf[4] = '-'
+		quotient = gs3 / 10
+		remainder = gs3 % 10
+		buf[5] = sftDigits[quotient]
+		buf[6] = sftDigits[remainder]
+		buf[7] = '-'
+		quotient = gs2 / 10
+		remainder = gs2 % 10
+		buf[8] = sftDigits[quotient]
+		buf[9] = sftDigits[remainder]
+		return buf[:10]
+	}
+
+	offset := 0
+	if gs0 >= 0 && gs0 < 10000 {
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[offset] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[offset+1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[offset+2] = sftDigits[quotient]
+		buf[offset+3] = sftDigits[remainder]
+		offset += 4
+	} else {
+		v := gs0
+		if v < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 4 {
+			offset = start + 4
+			copy(buf[start+4-n:], buf[start:start+n])
+			for i := start; i < start+4-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+	offset += copy(buf[offset:], "-")
+	quotient = gs3 / 10
+	remainder = gs3 % 10
+	buf[offset] = sftDigits[quotient]
+	buf[offset+1] = sftDigits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], "-")
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[offset] = sftDigits[quotient]
+	buf[offset+1] = sftDigits[remainder]
+	offset += 2
+
+	buf = buf[:offset]
+	return buf
+}
+
+// ISOYearShortMaxLen is the maximum length of the text formatted by ISOYearShort.
+const ISOYearShortMaxLen = 2
+
+// ISOYearShortFixedLen is the length of every text formatted by ISOYearShort.
+const ISOYearShortFixedLen = 2
+
+func ISOYearShort(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	gs0, _ := t.ISOWeek()
+	gs1 := gs0 % 100
+	gs2 := gs1 >> 63
+	gs3 := (gs1 ^ gs2) - gs2
+
+	quotient = gs3 / 10
+	remainder = gs3 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// ISOYearMaxLen is the maximum length of the text formatted by ISOYear.
+const ISOYearMaxLen = 20
+
+func ISOYear(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 20 {
+		buf = make([]byte, 20)
+	}
+
+	gs0, _ := t.ISOWeek()
+
+	if gs0 >= 0 && gs0 < 10000 {
+		_ = buf[3]
+
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[0] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[2] = sftDigits[quotient]
+		buf[3] = sftDigits[remainder]
+		return buf[:4]
+	}
+
+	offset := 0
+	if gs0 >= 0 && gs0 < 10000 {
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[offset] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[offset+1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[offset+2] = sftDigits[quotient]
+		buf[offset+3] = sftDigits[remainder]
+		offset += 4
+	} else {
+		v := gs0
+		if v < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 4 {
+			offset = start + 4
+			copy(buf[start+4-n:], buf[start:start+n])
+			for i := start; i < start+4-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+
+	buf = buf[:offset]
+	return buf
+}
+
+// HourMaxLen is the maximum length of the text formatted by Hour.
+const HourMaxLen = 2
+
+// HourFixedLen is the length of every text formatted by Hour.
+const HourFixedLen = 2
+
+func Hour(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	gs0, _, _ := t.Clock()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// Hour12MaxLen is the maximum length of the text formatted by Hour12.
+const Hour12MaxLen = 2
+
+// Hour12FixedLen is the length of every text formatted by Hour12.
+const Hour12FixedLen = 2
+
+func Hour12(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	gs0, _, _ := t.Clock()
+	gs1 := (gs0+11)%12 + 1
+
+	quotient = gs1 / 10
+	remainder = gs1 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// YearDayMaxLen is the maximum length of the text formatted by YearDay.
+const YearDayMaxLen = 3
+
+// YearDayFixedLen is the length of every text formatted by YearDay.
+const YearDayFixedLen = 3
+
+func YearDay(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 3 {
+		buf = make([]byte, 3)
+	}
+
+	_ = buf[2]
+
+	gs0 := t.YearDay()
+
+	quotient = gs0 / 100
+	remainder = gs0 % 100
+	buf[0] = sftDigits[quotient]
+	quotient = remainder / 10
+	remainder %= 10
+	buf[1] = sftDigits[quotient]
+	buf[2] = sftDigits[remainder]
+
+	buf = buf[:3]
+	return buf
+}
+
+// HourSpaceMaxLen is the maximum length of the text formatted by HourSpace.
+const HourSpaceMaxLen = 2
+
+// HourSpaceFixedLen is the length of every text formatted by HourSpace.
+const HourSpaceFixedLen = 2
+
+func HourSpace(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	gs0, _, _ := t.Clock()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[10+quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// Hour12SpaceMaxLen is the maximum length of the text formatted by Hour12Space.
+const Hour12SpaceMaxLen = 2
+
+// Hour12SpaceFixedLen is the length of every text formatted by Hour12Space.
+const Hour12SpaceFixedLen = 2
+
+func Hour12Space(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	gs0, _, _ := t.Clock()
+	gs1 := (gs0+11)%12 + 1
+
+	quotient = gs1 / 10
+	remainder = gs1 % 10
+	buf[0] = sftDigits[10+quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// MonthMaxLen is the maximum length of the text formatted by Month.
+const MonthMaxLen = 2
+
+// MonthFixedLen is the length of every text formatted by Month.
+const MonthFixedLen = 2
+
+func Month(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	_, gs0, _ := t.Date()
+	gs1 := int(gs0)
+
+	quotient = gs1 / 10
+	remainder = gs1 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// MinuteMaxLen is the maximum length of the text formatted by Minute.
+const MinuteMaxLen = 2
+
+// MinuteFixedLen is the length of every text formatted by Minute.
+const MinuteFixedLen = 2
+
+func Minute(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	_, gs0, _ := t.Clock()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// NanosMaxLen is the maximum length of the text formatted by Nanos.
+const NanosMaxLen = 9
+
+// NanosFixedLen is the length of every text formatted by Nanos.
+const NanosFixedLen = 9
+
+func Nanos(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 9 {
+		buf = make([]byte, 9)
+	}
+
+	_ = buf[8]
+
+	gs0 := t.Nanosecond()
+
+	quotient = gs0 / 100000000
+	remainder = gs0 % 100000000
+	buf[0] = sftDigits[quotient]
+	quotient = remainder / 10000000
+	remainder %= 10000000
+	buf[1] = sftDigits[quotient]
+	quotient = remainder / 1000000
+	remainder %= 1000000
+	buf[2] = sftDigits[quotient]
+	quotient = remainder / 100000
+	remainder %= 100000
+	buf[3] = sftDigits[quotient]
+	quotient = remainder / 10000
+	remainder %= 10000
+	buf[4] = sftDigits[quotient]
+	quotient = remainder / 1000
+	remainder %= 1000
+	buf[5] = sftDigits[quotient]
+	quotient = remainder / 100
+	remainder %= 100
+	buf[6] = sftDigits[quotient]
+	quotient = remainder / 10
+	remainder %= 10
+	buf[7] = sftDigits[quotient]
+	buf[8] = sftDigits[remainder]
+
+	buf = buf[:9]
+	return buf
+}
+
+// Time12MaxLen is the maximum length of the text formatted by Time12.
+const Time12MaxLen = 11
+
+// Time12FixedLen is the length of every text formatted by Time12.
+const Time12FixedLen = 11
+
+func Time12(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 11 {
+		buf = make([]byte, 11)
+	}
+
+	_ = buf[10]
+
+	gs2, gs0, gs1 := t.Clock()
+	gs3 := (gs2+11)%12 + 1
+	gs4 := sftAmpmIndex[gs2]
+
+	quotient = gs3 / 10
+	remainder = gs3 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+	buf[2] = ':'
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[3] = sftDigits[quotient]
+	buf[4] = sftDigits[remainder]
+	buf[5] = ':'
+	quotient = gs1 / 10
+	remainder = gs1 % 10
+	buf[6] = sftDigits[quotient]
+	buf[7] = sftDigits[remainder]
+	buf[8] = ' '
+
+	buf[9] = sftAmpmc[gs4]
+	buf[10] = sftAmpmc[gs4+1]
+
+	buf = buf[:11]
+	return buf
+}
+
+// HourMinuteMaxLen is the maximum length of the text formatted by HourMinute.
+const HourMinuteMaxLen = 5
+
+// HourMinuteFixedLen is the length of every text formatted by HourMinute.
+const HourMinuteFixedLen = 5
+
+func HourMinute(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 5 {
+		buf = make([]byte, 5)
+	}
+
+	_ = buf[4]
+
+	gs0, gs1, _ := t.Clock()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+	buf[2] = ':'
+	quotient = gs1 / 10
+	remainder = gs1 % 10
+	buf[3] = sftDigits[quotient]
+	buf[4] = sftDigits[remainder]
+
+	buf = buf[:5]
+	return buf
+}
+
+// SecondMaxLen is the maximum length of the text formatted by Second.
+const SecondMaxLen = 2
+
+// SecondFixedLen is the length of every text formatted by Second.
+const SecondFixedLen = 2
+
+func Second(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	_, _, gs0 := t.Clock()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// TimeMaxLen is the maximum length of the text formatted by Time.
+const TimeMaxLen = 8
+
+// TimeFixedLen is the length of every text formatted by Time.
+const TimeFixedLen = 8
+
+func Time(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 8 {
+		buf = make([]byte, 8)
+	}
+
+	_ = buf[7]
+
+	gs0, gs1, gs2 := t.Clock()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+	buf[2] = ':'
+	quotient = gs1 / 10
+	remainder = gs1 % 10
+	buf[3] = sftDigits[quotient]
+	buf[4] = sftDigits[remainder]
+	buf[5] = ':'
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[6] = sftDigits[quotient]
+	buf[7] = sftDigits[remainder]
+
+	buf = buf[:8]
+	return buf
+}
+
+// SundayWeekMaxLen is the maximum length of the text formatted by SundayWeek.
+const SundayWeekMaxLen = 2
+
+// SundayWeekFixedLen is the length of every text formatted by SundayWeek.
+const SundayWeekFixedLen = 2
+
+func SundayWeek(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	gs0 := t.YearDay()
+	gs1 := t.Weekday()
+	gs2 := (gs0 + 6 - int(gs1)) / 7
+
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// ISOWeekMaxLen is the maximum length of the text formatted by ISOWeek.
+const ISOWeekMaxLen = 2
+
+// ISOWeekFixedLen is the length of every text formatted by ISOWeek.
+const ISOWeekFixedLen = 2
+
+func ISOWeek(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	_, gs0 := t.ISOWeek()
+
+	quotient = gs0 / 10
+	remainder = gs0 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// MondayWeekMaxLen is the maximum length of the text formatted by MondayWeek.
+const MondayWeekMaxLen = 2
+
+// MondayWeekFixedLen is the length of every text formatted by MondayWeek.
+const MondayWeekFixedLen = 2
+
+func MondayWeek(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	gs0 := t.YearDay()
+	gs1 := t.Weekday()
+	gs2 := (gs0 + 6 - (int(gs1)+6)%7) / 7
+
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// YearMaxLen is the maximum length of the text formatted by Year.
+const YearMaxLen = 20
+
+func Year(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 20 {
+		buf = make([]byte, 20)
+	}
+
+	gs0, _, _ := t.Date()
+
+	if gs0 >= 0 && gs0 < 10000 {
+		_ = buf[3]
+
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[0] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[2] = sftDigits[quotient]
+		buf[3] = sftDigits[remainder]
+		return buf[:4]
+	}
+
+	offset := 0
+	if gs0 >= 0 && gs0 < 10000 {
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[offset] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[offset+1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[offset+2] = sftDigits[quotient]
+		buf[offset+3] = sftDigits[remainder]
+		offset += 4
+	} else {
+		v := gs0
+		if v < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 4 {
+			offset = start + 4
+			copy(buf[start+4-n:], buf[start:start+n])
+			for i := start; i < start+4-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+
+	buf = buf[:offset]
+	return buf
+}
+
+// YearShortMaxLen is the maximum length of the text formatted by YearShort.
+const YearShortMaxLen = 2
+
+// YearShortFixedLen is the length of every text formatted by YearShort.
+const YearShortFixedLen = 2
+
+func YearShort(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 2 {
+		buf = make([]byte, 2)
+	}
+
+	_ = buf[1]
+
+	gs0, _, _ := t.Date()
+	gs1 := gs0 % 100
+	gs2 := gs1 >> 63
+	gs3 := (gs1 ^ gs2) - gs2
+
+	quotient = gs3 / 10
+	remainder = gs3 % 10
+	buf[0] = sftDigits[quotient]
+	buf[1] = sftDigits[remainder]
+
+	buf = buf[:2]
+	return buf
+}
+
+// OffsetMaxLen is the maximum length of the text formatted by Offset.
+const OffsetMaxLen = 5
+
+func Offset(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 5 {
+		buf = make([]byte, 5)
+	}
+
+	_, gs0 := t.Zone()
+	gs1 := -gs0
+	gs2 := gs0 / 3600
+	gs3 := gs0 % 3600 / 60
+	gs4 := gs1 / 3600
+	gs5 := gs1 % 3600 / 60
+
+	offset := 0
+
+	if gs0 >= 0 {
+		buf[offset] = '+'
+		offset++
+		quotient = gs2 / 10
+		remainder = gs2 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+		quotient = gs3 / 10
+		remainder = gs3 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+	} else {
+		buf[offset] = '-'
+		offset++
+		quotient = gs4 / 10
+		remainder = gs4 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+		quotient = gs5 / 10
+		remainder = gs5 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+	}
+
+	buf = buf[:offset]
+	return buf
+}
+
+// OffsetColonsMaxLen is the maximum length of the text formatted by OffsetColons.
+const OffsetColonsMaxLen = 9
+
+func OffsetColons(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 9 {
+		buf = make([]byte, 9)
+	}
+
+	_, gs0 := t.Zone()
+	gs1 := -gs0
+	gs2 := gs0 / 3600
+	gs3 := gs0 % 3600 / 60
+	gs4 := gs0 % 60
+	gs5 := gs1 / 3600
+	gs6 := gs1 % 3600 / 60
+	gs7 := gs1 % 60
+
+	offset := 0
+
+	if gs0 >= 0 {
+		buf[offset] = '+'
+		offset++
+		quotient = gs2 / 10
+		remainder = gs2 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+		if gs0%3600 != 0 {
+			buf[offset] = ':'
+			offset++
+			quotient = gs3 / 10
+			remainder = gs3 % 10
+			buf[offset] = sftDigits[quotient]
+			buf[offset+1] = sftDigits[remainder]
+			offset += 2
+			if gs0%60 != 0 {
+				buf[offset] = ':'
+				offset++
+				quotient = gs4 / 10
+				remainder = gs4 % 10
+				buf[offset] = sftDigits[quotient]
+				buf[offset+1] = sftDigits[remainder]
+				offset += 2
+			}
+		}
+	} else {
+		buf[offset] = '-'
+		offset++
+		quotient = gs5 / 10
+		remainder = gs5 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+		if gs1%3600 != 0 {
+			buf[offset] = ':'
+			offset++
+			quotient = gs6 / 10
+			remainder = gs6 % 10
+			buf[offset] = sftDigits[quotient]
+			buf[offset+1] = sftDigits[remainder]
+			offset += 2
+			if gs1%60 != 0 {
+				buf[offset] = ':'
+				offset++
+				quotient = gs7 / 10
+				remainder = gs7 % 10
+				buf[offset] = sftDigits[quotient]
+				buf[offset+1] = sftDigits[remainder]
+				offset += 2
+			}
+		}
+	}
+
+	buf = buf[:offset]
+	return buf
+}
+
+// CtimeMaxLen is the maximum length of the text formatted by Ctime.
+const CtimeMaxLen = 40
+
+func Ctime(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 40 {
+		buf = make([]byte, 40)
+	}
+
+	gs0 := t.Weekday()
+	gs1 := sftWeekdaysLongIndices[gs0]
+	gs11, gs3, gs7 := t.Date()
+	gs4 := gs3 - 1
+	gs5 := sftMonthsLongIndices[gs4]
+	gs8, gs9, gs10 := t.Clock()
+
+	if gs11 >= 0 && gs11 < 10000 {
+		_ = buf[23]
+
+		buf[0] = sftWeekdaysLong[gs1]
+		buf[1] = sftWeekdaysLong[gs1+1]
+		buf[2] = sftWeekdaysLong[gs1+2]
+		buf[3] = ' '
+
+		buf[4] = sftMonthsLong[gs5]
+		buf[5] = sftMonthsLong[gs5+1]
+		buf[6] = sftMonthsLong[gs5+2]
+		buf[7] = ' '
+
+		quotient = gs7 / 10
+		remainder = gs7 % 10
+		buf[8] = sftDigits[10+quotient]
+		buf[9] = sftDigits[remainder]
+		buf[10] = ' '
+
+		quotient = gs8 / 10
+		remainder = gs8 % 10
+		buf[11] = sftDigits[quotient]
+		buf[12] = sftDigits[remainder]
+		buf[13] = ':'
+		quotient = gs9 / 10
+		remainder = gs9 % 10
+		buf[14] = sftDigits[quotient]
+		buf[15] = sftDigits[remainder]
+		buf[16] = ':'
+		quotient = gs10 / 10
+		remainder = gs10 % 10
+		buf[17] = sftDigits[quotient]
+		buf[18] = sftDigits[remainder]
+		buf[19] = ' '
+
+		quotient = gs11 / 1000
+		remainder = gs11 % 1000
+		buf[20] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[21] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[22] = sftDigits[quotient]
+		buf[23] = sftDigits[remainder]
+		return buf[:24]
+	}
+
+	buf[0] = sftWeekdaysLong[gs1]
+	buf[1] = sftWeekdaysLong[gs1+1]
+	buf[2] = sftWeekdaysLong[gs1+2]
+	buf[3] = ' '
+
+	buf[4] = sftMonthsLong[gs5]
+	buf[5] = sftMonthsLong[gs5+1]
+	buf[6] = sftMonthsLong[gs5+2]
+	buf[7] = ' '
+
+	quotient = gs7 / 10
+	remainder = gs7 % 10
+	buf[8] = sftDigits[10+quotient]
+	buf[9] = sftDigits[remainder]
+	buf[10] = ' '
+
+	quotient = gs8 / 10
+	remainder = gs8 % 10
+	buf[11] = sftDigits[quotient]
+	buf[12] = sftDigits[remainder]
+	buf[13] = ':'
+	quotient = gs9 / 10
+	remainder = gs9 % 10
+	buf[14] = sftDigits[quotient]
+	buf[15] = sftDigits[remainder]
+	buf[16] = ':'
+	quotient = gs10 / 10
+	remainder = gs10 % 10
+	buf[17] = sftDigits[quotient]
+	buf[18] = sftDigits[remainder]
+	buf[19] = ' '
+
+	offset := 20
+	if gs11 >= 0 && gs11 < 10000 {
+		quotient = gs11 / 1000
+		remainder = gs11 % 1000
+		buf[offset] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[offset+1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[offset+2] = sftDigits[quotient]
+		buf[offset+3] = sftDigits[remainder]
+		offset += 4
+	} else {
+		v := gs11
+		if v < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 4 {
+			offset = start + 4
+			copy(buf[start+4-n:], buf[start:start+n])
+			for i := start; i < start+4-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+
+	buf = buf[:offset]
+	return buf
+}
+
+func DateTime(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	gs0 := t.Weekday()
+	gs1 := sftWeekdaysLongIndices[gs0]
+	gs14, gs3, gs7 := t.Date()
+	gs4 := gs3 - 1
+	gs5 := sftMonthsLongIndices[gs4]
+	gs8, gs9, gs10 := t.Clock()
+	gs11 := sftAmpmIndex[gs8]
+	gs13, _ := t.Zone()
+
+	if n := 44 + len(gs13); len(buf) < n {
+		buf = make([]byte, n)
+	}
+
+	buf[0] = sftWeekdaysLong[gs1]
+	buf[1] = sftWeekdaysLong[gs1+1]
+	buf[2] = sftWeekdaysLong[gs1+2]
+	buf[3] = ' '
+
+	buf[4] = sftMonthsLong[gs5]
+	buf[5] = sftMonthsLong[gs5+1]
+	buf[6] = sftMonthsLong[gs5+2]
+	buf[7] = ' '
+
+	quotient = gs7 / 10
+	remainder = gs7 % 10
+	buf[8] = sftDigits[10+quotient]
+	buf[9] = sftDigits[remainder]
+	buf[10] = ' '
+
+	quotient = gs8 / 10
+	remainder = gs8 % 10
+	buf[11] = sftDigits[quotient]
+	buf[12] = sftDigits[remainder]
+	buf[13] = ':'
+	quotient = gs9 / 10
+	remainder = gs9 % 10
+	buf[14] = sftDigits[quotient]
+	buf[15] = sftDigits[remainder]
+	buf[16] = ':'
+	quotient = gs10 / 10
+	remainder = gs10 % 10
+	buf[17] = sftDigits[quotient]
+	buf[18] = sftDigits[remainder]
+	buf[19] = ' '
+
+	buf[20] = sftAmpmc[gs11]
+	buf[21] = sftAmpmc[gs11+1]
+	buf[22] = ' '
+	offset := 23
+	offset += copy(buf[offset:], gs13)
+	offset += copy(buf[offset:], " ")
+
+	if gs14 >= 0 && gs14 < 10000 {
+		quotient = gs14 / 1000
+		remainder = gs14 % 1000
+		buf[offset] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[offset+1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[offset+2] = sftDigits[quotient]
+		buf[offset+3] = sftDigits[remainder]
+		offset += 4
+	} else {
+		v := gs14
+		if v < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 4 {
+			offset = start + 4
+			copy(buf[start+4-n:], buf[start:start+n])
+			for i := start; i < start+4-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+
+	buf = buf[:offset]
+	return buf
+}
+
+// ZuluMaxLen is the maximum length of the text formatted by Zulu.
+const ZuluMaxLen = 6
+
+func Zulu(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 6 {
+		buf = make([]byte, 6)
+	}
+
+	_, gs0 := t.Zone()
+	gs1 := gs0 / 3600
+	gs2 := gs0 % 3600 / 60
+	gs3 := -gs0
+	gs4 := gs3 / 3600
+	gs5 := gs3 % 3600 / 60
+
+	offset := 0
+
+	if gs0 == 0 {
+		buf[offset] = 'Z'
+		offset++
+	} else if gs0 > 0 {
+		buf[offset] = '+'
+		offset++
+		quotient = gs1 / 10
+		remainder = gs1 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+		buf[offset] = ':'
+		offset++
+		quotient = gs2 / 10
+		remainder = gs2 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+	} else {
+		buf[offset] = '-'
+		offset++
+		quotient = gs4 / 10
+		remainder = gs4 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+		buf[offset] = ':'
+		offset++
+		quotient = gs5 / 10
+		remainder = gs5 % 10
+		buf[offset] = sftDigits[quotient]
+		buf[offset+1] = sftDigits[remainder]
+		offset += 2
+	}
+
+	buf = buf[:offset]
+	return buf
+}
+
+// MillisMaxLen is the maximum length of the text formatted by Millis.
+const MillisMaxLen = 3
+
+// MillisFixedLen is the length of every text formatted by Millis.
+const MillisFixedLen = 3
+
+func Millis(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 3 {
+		buf = make([]byte, 3)
+	}
+
+	_ = buf[2]
+
+	gs0 := t.Nanosecond()
+	gs1 := gs0 / 1000000
+
+	quotient = gs1 / 100
+	remainder = gs1 % 100
+	buf[0] = sftDigits[quotient]
+	quotient = remainder / 10
+	remainder %= 10
+	buf[1] = sftDigits[quotient]
+	buf[2] = sftDigits[remainder]
+
+	buf = buf[:3]
+	return buf
+}
+
+// MicrosMaxLen is the maximum length of the text formatted by Micros.
+const MicrosMaxLen = 6
+
+// MicrosFixedLen is the length of every text formatted by Micros.
+const MicrosFixedLen = 6
+
+func Micros(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 6 {
+		buf = make([]byte, 6)
+	}
+
+	_ = buf[5]
+
+	gs0 := t.Nanosecond()
+	gs1 := gs0 / 1000
+
+	quotient = gs1 / 100000
+	remainder = gs1 % 100000
+	buf[0] = sftDigits[quotient]
+	quotient = remainder / 10000
+	remainder %= 10000
+	buf[1] = sftDigits[quotient]
+	quotient = remainder / 1000
+	remainder %= 1000
+	buf[2] = sftDigits[quotient]
+	quotient = remainder / 100
+	remainder %= 100
+	buf[3] = sftDigits[quotient]
+	quotient = remainder / 10
+	remainder %= 10
+	buf[4] = sftDigits[quotient]
+	buf[5] = sftDigits[remainder]
+
+	buf = buf[:6]
+	return buf
+}
+
+// StampMaxLen is the maximum length of the text formatted by Stamp.
+const StampMaxLen = 45
+
+func Stamp(buf []byte, t time.Time) []byte {
+	var quotient, remainder int
+
+	if len(buf) < 45 {
+		buf = make([]byte, 45)
+	}
+
+	gs0, gs1, gs2 := t.Date()
+	gs3 := int(gs1)
+	gs4, gs5, gs6 := t.Clock()
+	gs7 := t.Nanosecond()
+
+	if gs0 >= 0 && gs0 < 10000 {
+		_ = buf[28]
+
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[0] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[2] = sftDigits[quotient]
+		buf[3] = sftDigits[remainder]
+		buf[4] = '-'
+		quotient = gs3 / 10
+		remainder = gs3 % 10
+		buf[5] = sftDigits[quotient]
+		buf[6] = sftDigits[remainder]
+		buf[7] = '-'
+		quotient = gs2 / 10
+		remainder = gs2 % 10
+		buf[8] = sftDigits[quotient]
+		buf[9] = sftDigits[remainder]
+		buf[10] = ' '
+
+		quotient = gs4 / 10
+		remainder = gs4 % 10
+		buf[11] = sftDigits[quotient]
+		buf[12] = sftDigits[remainder]
+		buf[13] = ':'
+		quotient = gs5 / 10
+		remainder = gs5 % 10
+		buf[14] = sftDigits[quotient]
+		buf[15] = sftDigits[remainder]
+		buf[16] = ':'
+		quotient = gs6 / 10
+		remainder = gs6 % 10
+		buf[17] = sftDigits[quotient]
+		buf[18] = sftDigits[remainder]
+		buf[19] = '.'
+
+		quotient = gs7 / 100000000
+		remainder = gs7 % 100000000
+		buf[20] = sftDigits[quotient]
+		quotient = remainder / 10000000
+		remainder %= 10000000
+		buf[21] = sftDigits[quotient]
+		quotient = remainder / 1000000
+		remainder %= 1000000
+		buf[22] = sftDigits[quotient]
+		quotient = remainder / 100000
+		remainder %= 100000
+		buf[23] = sftDigits[quotient]
+		quotient = remainder / 10000
+		remainder %= 10000
+		buf[24] = sftDigits[quotient]
+		quotient = remainder / 1000
+		remainder %= 1000
+		buf[25] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[26] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[27] = sftDigits[quotient]
+		buf[28] = sftDigits[remainder]
+		return buf[:29]
+	}
+
+	offset := 0
+	if gs0 >= 0 && gs0 < 10000 {
+		quotient = gs0 / 1000
+		remainder = gs0 % 1000
+		buf[offset] = sftDigits[quotient]
+		quotient = remainder / 100
+		remainder %= 100
+		buf[offset+1] = sftDigits[quotient]
+		quotient = remainder / 10
+		remainder %= 10
+		buf[offset+2] = sftDigits[quotient]
+		buf[offset+3] = sftDigits[remainder]
+		offset += 4
+	} else {
+		v := gs0
+		if v < 0 {
+			buf[offset] = '-'
+			offset++
+			v = -v
+		}
+		start := offset
+		offset += len(strconv.AppendInt(buf[offset:offset], int64(v), 10))
+		if n := offset - start; n < 4 {
+			offset = start + 4
+			copy(buf[start+4-n:], buf[start:start+n])
+			for i := start; i < start+4-n; i++ {
+				buf[i] = '0'
+			}
+		}
+	}
+	offset += copy(buf[offset:], "-")
+	quotient = gs3 / 10
+	remainder = gs3 % 10
+	buf[offset] = sftDigits[quotient]
+	buf[offset+1] = sftDigits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], "-")
+	quotient = gs2 / 10
+	remainder = gs2 % 10
+	buf[offset] = sftDigits[quotient]
+	buf[offset+1] = sftDigits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], " ")
+
+	quotient = gs4 / 10
+	remainder = gs4 % 10
+	buf[offset] = sftDigits[quotient]
+	buf[offset+1] = sftDigits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], ":")
+	quotient = gs5 / 10
+	remainder = gs5 % 10
+	buf[offset] = sftDigits[quotient]
+	buf[offset+1] = sftDigits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], ":")
+	quotient = gs6 / 10
+	remainder = gs6 % 10
+	buf[offset] = sftDigits[quotient]
+	buf[offset+1] = sftDigits[remainder]
+	offset += 2
+	offset += copy(buf[offset:], ".")
+
+	quotient = gs7 / 100000000
+	remainder = gs7 % 100000000
+	buf[offset] = sftDigits[quotient]
+	quotient = remainder / 10000000
+	remainder %= 10000000
+	buf[offset+1] = sftDigits[quotient]
+	quotient = remainder / 1000000
+	remainder %= 1000000
+	buf[offset+2] = sftDigits[quotient]
+	quotient = remainder / 100000
+	remainder %= 100000
+	buf[offset+3] = sftDigits[quotient]
+	quotient = remainder / 10000
+	remainder %= 10000
+	buf[offset+4] = sftDigits[quotient]
+	quotient = remainder / 1000
+	remainder %= 1000
+	buf[offset+5] = sftDigits[quotient]
+	quotient = remainder / 100
+	remainder %= 100
+	buf[offset+6] = sftDigits[quotient]
+	quotient = remainder / 10
+	remainder %= 10
+	buf[offset+7] = sftDigits[quotient]
+	buf[offset+8] = sftDigits[remainder]
+	offset += 9
+
+	buf = buf[:offset]
+	return buf
+}
@@ -0,0 +1,9 @@
+// Package pairsbench compares the two ways the generator writes numbers, for
+// every formatting verb that writes one. Package divide holds the functions
+// emitted by default, which divide once per digit and look each digit up in
+// a table of ten. Package pairs holds the same functions emitted with
+// Config.DigitPairs, which divide once per two digits and look both up in a
+// table of the hundred pairs "00" through "99". The functions are named in
+// verbs.txt. Regenerate both packages with "make pairsbench", which also
+// runs the benchmarks.
+package pairsbench
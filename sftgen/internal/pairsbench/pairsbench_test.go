@@ -0,0 +1,84 @@
+package pairsbench
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karrick/sft/sftgen/internal/pairsbench/divide"
+	"github.com/karrick/sft/sftgen/internal/pairsbench/pairs"
+)
+
+var verbs = []struct {
+	name          string
+	divide, pairs func([]byte, time.Time) []byte
+}{
+	{"Century", divide.Century, pairs.Century},
+	{"Day", divide.Day, pairs.Day},
+	{"DaySpace", divide.DaySpace, pairs.DaySpace},
+	{"Date", divide.Date, pairs.Date},
+	{"Full", divide.Full, pairs.Full},
+	{"ISOYearShort", divide.ISOYearShort, pairs.ISOYearShort},
+	{"ISOYear", divide.ISOYear, pairs.ISOYear},
+	{"Hour", divide.Hour, pairs.Hour},
+	{"Hour12", divide.Hour12, pairs.Hour12},
+	{"YearDay", divide.YearDay, pairs.YearDay},
+	{"HourSpace", divide.HourSpace, pairs.HourSpace},
+	{"Hour12Space", divide.Hour12Space, pairs.Hour12Space},
+	{"Month", divide.Month, pairs.Month},
+	{"Minute", divide.Minute, pairs.Minute},
+	{"Nanos", divide.Nanos, pairs.Nanos},
+	{"Time12", divide.Time12, pairs.Time12},
+	{"HourMinute", divide.HourMinute, pairs.HourMinute},
+	{"Second", divide.Second, pairs.Second},
+	{"Time", divide.Time, pairs.Time},
+	{"SundayWeek", divide.SundayWeek, pairs.SundayWeek},
+	{"ISOWeek", divide.ISOWeek, pairs.ISOWeek},
+	{"MondayWeek", divide.MondayWeek, pairs.MondayWeek},
+	{"Year", divide.Year, pairs.Year},
+	{"YearShort", divide.YearShort, pairs.YearShort},
+	{"Offset", divide.Offset, pairs.Offset},
+	{"OffsetColons", divide.OffsetColons, pairs.OffsetColons},
+	{"Ctime", divide.Ctime, pairs.Ctime},
+	{"DateTime", divide.DateTime, pairs.DateTime},
+	{"Zulu", divide.Zulu, pairs.Zulu},
+	{"Millis", divide.Millis, pairs.Millis},
+	{"Micros", divide.Micros, pairs.Micros},
+	{"Stamp", divide.Stamp, pairs.Stamp},
+}
+
+func TestPairs(t *testing.T) {
+	for _, when := range []time.Time{
+		time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC),
+		time.Date(1999, time.December, 31, 23, 59, 59, 999999999, time.FixedZone("XYZ", -(3*3600+30*60))),
+		time.Date(-44, time.March, 15, 0, 0, 0, 7, time.UTC),
+		time.Date(10000, time.October, 9, 8, 7, 6, 5, time.FixedZone("ABC", 14*3600)),
+	} {
+		for _, v := range verbs {
+			if got, want := string(v.pairs(nil, when)), string(v.divide(nil, when)); got != want {
+				t.Errorf("%s: GOT: %q; WANT: %q", v.name, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkVerbs(b *testing.B) {
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)
+	for _, v := range verbs {
+		for _, f := range []struct {
+			name   string
+			format func([]byte, time.Time) []byte
+		}{
+			{"divide", v.divide},
+			{"pairs", v.pairs},
+		} {
+			b.Run(v.name+"/"+f.name, func(b *testing.B) {
+				buf := make([]byte, 64)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_ = f.format(buf, when)
+				}
+			})
+		}
+	}
+}
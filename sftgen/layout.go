@@ -0,0 +1,145 @@
+package sftgen
+
+// Verbs that only result from scanning Go reference layouts, for the layout
+// elements that have no strftime equivalent. They use runes from the Unicode
+// private use area so they cannot collide with strftime verbs.
+const (
+	verbLayoutZone     rune = 0xE000 + iota // MST: zone abbreviation, or -0700 when the zone has none
+	verbLayoutOffset                        // Z07:00, -07, etc.: zone offset described by zulu, colon, and precision
+	verbLayoutFraction                      // .000 or .999: fractional second described by separator, width, and trim
+)
+
+// layoutElement is a Go reference layout element, along with the directive
+// that writes the same text that time.Format writes for it.
+type layoutElement struct {
+	element string
+	d       directive
+}
+
+// layoutElements lists the layout elements recognized by time.Format,
+// ordered such that when one element is a prefix of another, the longer
+// element is listed first.
+var layoutElements = []layoutElement{
+	{"January", directive{verb: 'B'}},
+	{"Jan", directive{verb: 'b'}},
+	{"Monday", directive{verb: 'A'}},
+	{"Mon", directive{verb: 'a'}},
+	{"MST", directive{verb: verbLayoutZone}},
+	{"002", directive{verb: 'j'}},
+	{"01", directive{verb: 'm'}},
+	{"02", directive{verb: 'd'}},
+	{"03", directive{verb: 'I'}},
+	{"04", directive{verb: 'M'}},
+	{"05", directive{verb: 'S'}},
+	{"06", directive{verb: 'y'}},
+	{"15", directive{verb: 'H'}},
+	{"1", directive{verb: 'm', pad: '-'}},
+	{"2006", directive{verb: 'Y'}},
+	{"2", directive{verb: 'd', pad: '-'}},
+	{"__2", directive{verb: 'j', pad: '_'}},
+	{"_2", directive{verb: 'e'}},
+	{"3", directive{verb: 'I', pad: '-'}},
+	{"4", directive{verb: 'M', pad: '-'}},
+	{"5", directive{verb: 'S', pad: '-'}},
+	{"PM", directive{verb: 'p'}},
+	{"pm", directive{verb: 'P'}},
+	{"-070000", directive{verb: verbLayoutOffset, precision: 3}},
+	{"-07:00:00", directive{verb: verbLayoutOffset, colon: true, precision: 3}},
+	{"-0700", directive{verb: verbLayoutOffset, precision: 2}},
+	{"-07:00", directive{verb: verbLayoutOffset, colon: true, precision: 2}},
+	{"-07", directive{verb: verbLayoutOffset, precision: 1}},
+	{"Z070000", directive{verb: verbLayoutOffset, zulu: true, precision: 3}},
+	{"Z07:00:00", directive{verb: verbLayoutOffset, zulu: true, colon: true, precision: 3}},
+	{"Z0700", directive{verb: verbLayoutOffset, zulu: true, precision: 2}},
+	{"Z07:00", directive{verb: verbLayoutOffset, zulu: true, colon: true, precision: 2}},
+	{"Z07", directive{verb: verbLayoutOffset, zulu: true, precision: 1}},
+}
+
+// scanLayout splits a Go reference layout into a sequence of directives,
+// following the same rules time.Format uses to find layout elements, so that
+// the generated function writes the same text as calling time.Format with
+// the layout.
+func scanLayout(layout string) []directive {
+	var directives []directive
+	var stringConstant []byte
+
+	appendDirective := func(d directive) {
+		if len(stringConstant) > 0 {
+			directives = append(directives, directive{literal: string(stringConstant)})
+			stringConstant = stringConstant[:0]
+		}
+		directives = append(directives, d)
+	}
+
+	for i := 0; i < len(layout); {
+		if d, n := layoutDirective(layout, i); n > 0 {
+			d.index = i
+			appendDirective(d)
+			i += n
+			continue
+		}
+		stringConstant = append(stringConstant, layout[i])
+		i++
+	}
+
+	if len(stringConstant) > 0 {
+		directives = append(directives, directive{literal: string(stringConstant)})
+	}
+
+	return directives
+}
+
+// layoutDirective returns the directive for the layout element that starts
+// at index i of layout, along with the length of the element, or a length of
+// zero when no element starts there.
+func layoutDirective(layout string, i int) (directive, int) {
+	rest := layout[i:]
+
+	switch c := rest[0]; c {
+	case '_':
+		// "_2006" is a literal underscore followed by a year.
+		if len(rest) >= 5 && rest[1:5] == "2006" {
+			return directive{}, 0
+		}
+	case '.', ',':
+		// A separator followed by a run of zeros or nines, that is not
+		// followed by another digit, is a fractional second. Like
+		// time.Format, no more than nine digits are written however long
+		// the run.
+		if len(rest) > 1 && (rest[1] == '0' || rest[1] == '9') {
+			j := 1
+			for j < len(rest) && rest[j] == rest[1] {
+				j++
+			}
+			if j == len(rest) || rest[j] < '0' || rest[j] > '9' {
+				width := j - 1
+				if width > 9 {
+					width = 9
+				}
+				return directive{
+					verb:      verbLayoutFraction,
+					separator: c,
+					width:     width,
+					trim:      rest[1] == '9',
+				}, j
+			}
+		}
+		return directive{}, 0
+	}
+
+	for _, le := range layoutElements {
+		if len(rest) < len(le.element) || rest[:len(le.element)] != le.element {
+			continue
+		}
+		switch le.element {
+		case "Jan", "Mon":
+			// Like time.Format, "Janet" and "Month" are not elements.
+			if len(rest) > 3 && 'a' <= rest[3] && rest[3] <= 'z' {
+				return directive{}, 0
+			}
+		}
+		return le.d, len(le.element)
+	}
+
+	return directive{}, 0
+}
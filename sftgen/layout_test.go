@@ -0,0 +1,103 @@
+package sftgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanLayout(t *testing.T) {
+	cases := []struct {
+		layout string
+		want   []directive
+	}{
+		{
+			layout: "2006-01-02 15:04:05.000 MST",
+			want: []directive{
+				{verb: 'Y', index: 0},
+				{literal: "-"},
+				{verb: 'm', index: 5},
+				{literal: "-"},
+				{verb: 'd', index: 8},
+				{literal: " "},
+				{verb: 'H', index: 11},
+				{literal: ":"},
+				{verb: 'M', index: 14},
+				{literal: ":"},
+				{verb: 'S', index: 17},
+				{verb: verbLayoutFraction, index: 19, separator: '.', width: 3},
+				{literal: " "},
+				{verb: verbLayoutZone, index: 24},
+			},
+		},
+		{
+			layout: "Jan _2 3:4:5,999 Z07:00",
+			want: []directive{
+				{verb: 'b', index: 0},
+				{literal: " "},
+				{verb: 'e', index: 4},
+				{literal: " "},
+				{verb: 'I', index: 7, pad: '-'},
+				{literal: ":"},
+				{verb: 'M', index: 9, pad: '-'},
+				{literal: ":"},
+				{verb: 'S', index: 11, pad: '-'},
+				{verb: verbLayoutFraction, index: 12, separator: ',', width: 3, trim: true},
+				{literal: " "},
+				{verb: verbLayoutOffset, index: 17, zulu: true, colon: true, precision: 2},
+			},
+		},
+		{
+			// Like time.Format, neither "Janet" nor "Month" are layout
+			// elements, "_2006" is an underscore followed by a year, and a
+			// fractional second must not be followed by a digit.
+			layout: "Janet Month _2006 .0001",
+			want: []directive{
+				{literal: "Janet Month _"},
+				{verb: 'Y', index: 13},
+				{literal: " .00"},
+				{verb: 'm', index: 21},
+			},
+		},
+		{
+			// Like time.Format, a fractional second is written with no more
+			// than nine digits.
+			layout: "05.000000000000",
+			want: []directive{
+				{verb: 'S', index: 0},
+				{verb: verbLayoutFraction, index: 2, separator: '.', width: 9},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		got := scanLayout(c.layout)
+		if len(got) != len(c.want) {
+			t.Errorf("%q: GOT: %#v; WANT: %#v", c.layout, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("%q: GOT: %#v; WANT: %#v", c.layout, got[i], c.want[i])
+			}
+		}
+	}
+}
+
+func TestNewCodeGeneratorLayout(t *testing.T) {
+	cg, err := NewCodeGenerator("2006-01-02T15:04:05.999999999Z07:00", &Config{
+		Layout:        true,
+		ParseFuncName: "parseTime",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"func formatTime(buf []byte, t time.Time) []byte {\n",
+		"func parseTime(b []byte) (time.Time, error) {\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+}
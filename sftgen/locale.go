@@ -0,0 +1,321 @@
+package sftgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Locale holds the names that emitted functions write for weekdays, months,
+// and the AM/PM indicator. Names are UTF-8 strings of any length. The
+// formats of composite verbs, such as %c and %x, are not changed by a
+// locale.
+type Locale struct {
+	// Name identifies the locale in error messages.
+	Name string `json:"name"`
+
+	// WeekdaysLong and WeekdaysShort are the names of the weekdays, written
+	// by %A and %a, starting with Sunday.
+	WeekdaysLong  [7]string `json:"weekdaysLong"`
+	WeekdaysShort [7]string `json:"weekdaysShort"`
+
+	// MonthsLong and MonthsShort are the names of the months, written by %B
+	// and %b, starting with January.
+	MonthsLong  [12]string `json:"monthsLong"`
+	MonthsShort [12]string `json:"monthsShort"`
+
+	// AM and PM are written by %p, and in lower case by %P.
+	AM string `json:"am"`
+	PM string `json:"pm"`
+}
+
+// english is the locale used when Config.Locale is nil.
+var english = Locale{
+	Name:          "en",
+	WeekdaysLong:  [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	WeekdaysShort: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	MonthsLong:    [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	MonthsShort:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	AM:            "AM",
+	PM:            "PM",
+}
+
+// locales holds the built-in locales, by name. LookupLocale returns copies
+// of them, so that they cannot be changed by its callers.
+var locales = map[string]Locale{
+	"en": english,
+	"de": {
+		Name:          "de",
+		WeekdaysLong:  [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		WeekdaysShort: [7]string{"So", "Mo", "Di", "Mi", "Do", "Fr", "Sa"},
+		MonthsLong:    [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		MonthsShort:   [12]string{"Jan", "Feb", "Mär", "Apr", "Mai", "Jun", "Jul", "Aug", "Sep", "Okt", "Nov", "Dez"},
+		AM:            "AM",
+		PM:            "PM",
+	},
+	"es": {
+		Name:          "es",
+		WeekdaysLong:  [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		WeekdaysShort: [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		MonthsLong:    [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		MonthsShort:   [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sep", "oct", "nov", "dic"},
+		AM:            "a. m.",
+		PM:            "p. m.",
+	},
+	"fr": {
+		Name:          "fr",
+		WeekdaysLong:  [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		WeekdaysShort: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		MonthsLong:    [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		MonthsShort:   [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		AM:            "AM",
+		PM:            "PM",
+	},
+}
+
+// LookupLocale returns a copy of the built-in locale named name, which is one
+// of "de", "en", "es", or "fr".
+func LookupLocale(name string) (*Locale, error) {
+	if l, ok := locales[name]; ok {
+		return &l, nil
+	}
+	names := make([]string, 0, len(locales))
+	for n := range locales {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return nil, fmt.Errorf("cannot find locale %q; built-in locales are %s", name, strings.Join(names, ", "))
+}
+
+// ParseLocale reads a locale from a JSON object with the same field names as
+// the JSON encoding of Locale.
+//
+//	{
+//	    "name": "nl",
+//	    "weekdaysLong": ["zondag", "maandag", ...],
+//	    "weekdaysShort": ["zo", "ma", ...],
+//	    "monthsLong": ["januari", "februari", ...],
+//	    "monthsShort": ["jan", "feb", ...],
+//	    "am": "a.m.",
+//	    "pm": "p.m."
+//	}
+func ParseLocale(r io.Reader) (*Locale, error) {
+	blob, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	dec.DisallowUnknownFields()
+	l := new(Locale)
+	if err = dec.Decode(l); err != nil {
+		return nil, fmt.Errorf("cannot parse locale: %w", err)
+	}
+	if err = l.validate(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// validate returns an error when the locale is missing a name, or when
+// parsing could not tell AM from PM.
+func (l *Locale) validate() error {
+	for i, name := range l.WeekdaysLong {
+		if name == "" || l.WeekdaysShort[i] == "" {
+			return fmt.Errorf("cannot use locale %q without names for %s", l.Name, time.Weekday(i))
+		}
+	}
+	for i, name := range l.MonthsLong {
+		if name == "" || l.MonthsShort[i] == "" {
+			return fmt.Errorf("cannot use locale %q without names for %s", l.Name, time.Month(i+1))
+		}
+	}
+	if l.AM == "" || l.PM == "" || strings.EqualFold(l.AM, l.PM) {
+		return fmt.Errorf("cannot use locale %q without distinct AM and PM strings", l.Name)
+	}
+	return nil
+}
+
+// isEnglish returns true when the locale has the same names as the English
+// locale, whatever its own name.
+func (l *Locale) isEnglish() bool {
+	c := *l
+	c.Name = english.Name
+	return c == english
+}
+
+// nameTable is a table of names that emitted code slices out of a single
+// string constant by index, so that writing a name never allocates.
+type nameTable struct {
+	constant string // identifier of the string constant of the names
+	text     string // the names, concatenated
+	indices  string // identifier of the slice of offsets of the names
+	offsets  []int  // where each name starts, and where the previous one ends when width is 0
+	width    int    // byte length of every name, or 0 when their lengths differ
+	longest  int    // byte length of the longest name
+}
+
+// newNameTable returns the table of names, declared as the constants named
+// constant and indices.
+func newNameTable(constant, indices string, names []string) nameTable {
+	t := nameTable{
+		constant: constant,
+		text:     strings.Join(names, ""),
+		indices:  indices,
+		offsets:  make([]int, 0, len(names)+1),
+		width:    len(names[0]),
+	}
+	var offset int
+	for _, name := range names {
+		t.offsets = append(t.offsets, offset)
+		offset += len(name)
+		if len(name) != t.width {
+			t.width = 0
+		}
+		if len(name) > t.longest {
+			t.longest = len(name)
+		}
+	}
+	t.offsets = append(t.offsets, offset)
+	return t
+}
+
+// upperNameTable returns the table of the upper case names of t, declared as
+// the constant named constant. It shares the offsets of t, unless changing
+// the case of a name changes its length.
+func upperNameTable(t nameTable, constant, indices string, names []string) nameTable {
+	upper := make([]string, len(names))
+	for i, name := range names {
+		upper[i] = strings.ToUpper(name)
+	}
+	u := newNameTable(constant, indices, upper)
+	if equalOffsets(u.offsets, t.offsets) {
+		u.indices = t.indices
+	}
+	return u
+}
+
+func equalOffsets(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// weekdaysLong returns the table of long weekday names.
+func (l *Locale) weekdaysLong(upper bool) nameTable {
+	t := newNameTable("weekdaysLong", "weekdaysLongIndices", l.WeekdaysLong[:])
+	if upper {
+		return upperNameTable(t, "weekdaysLongUpper", "weekdaysLongUpperIndices", l.WeekdaysLong[:])
+	}
+	return t
+}
+
+// weekdaysShort returns the table of short weekday names. When they are
+// prefixes of the long names of the same length, they are sliced out of the
+// table of long names.
+func (l *Locale) weekdaysShort(upper bool) nameTable {
+	if t, ok := prefixTable(l.weekdaysLong(upper), l.WeekdaysLong[:], l.WeekdaysShort[:]); ok {
+		return t
+	}
+	t := newNameTable("weekdaysShort", "weekdaysShortIndices", l.WeekdaysShort[:])
+	if upper {
+		return upperNameTable(t, "weekdaysShortUpper", "weekdaysShortUpperIndices", l.WeekdaysShort[:])
+	}
+	return t
+}
+
+// monthsLong returns the table of long month names.
+func (l *Locale) monthsLong(upper bool) nameTable {
+	t := newNameTable("monthsLong", "monthsLongIndices", l.MonthsLong[:])
+	if upper {
+		return upperNameTable(t, "monthsLongUpper", "monthsLongUpperIndices", l.MonthsLong[:])
+	}
+	return t
+}
+
+// monthsShort returns the table of short month names. When they are prefixes
+// of the long names of the same length, they are sliced out of the table of
+// long names.
+func (l *Locale) monthsShort(upper bool) nameTable {
+	if t, ok := prefixTable(l.monthsLong(upper), l.MonthsLong[:], l.MonthsShort[:]); ok {
+		return t
+	}
+	t := newNameTable("monthsShort", "monthsShortIndices", l.MonthsShort[:])
+	if upper {
+		return upperNameTable(t, "monthsShortUpper", "monthsShortUpperIndices", l.MonthsShort[:])
+	}
+	return t
+}
+
+// prefixTable returns the table of long names, restricted to the width of
+// the short names, when every short name is a prefix of its long name of the
+// same length, and when changing the case of the names does not change their
+// lengths.
+func prefixTable(t nameTable, long, short []string) (nameTable, bool) {
+	width := len(short[0])
+	for i, name := range short {
+		if len(name) != width || !strings.HasPrefix(long[i], name) || len(strings.ToUpper(name)) != width {
+			return nameTable{}, false
+		}
+	}
+	if t.width == 0 && !equalOffsets(t.offsets, newNameTable("", "", long).offsets) {
+		return nameTable{}, false
+	}
+	t.width, t.longest = width, width
+	return t, true
+}
+
+// ampm returns the table of the AM/PM indicator, indexed by the hour. Its
+// offsets for the hours 12 through 35 are where the indicator for the hours
+// 0 through 23 ends. The indicator is in upper case when upper is true, in
+// lower case when lower is true, and as written in the locale otherwise.
+func (l *Locale) ampm(upper, lower bool) nameTable {
+	am, pm := l.AM, l.PM
+	constant, indices := "ampmc", "ampmIndex"
+	switch {
+	case lower:
+		am, pm = strings.ToLower(am), strings.ToLower(pm)
+		constant = "ampm"
+	case upper:
+		am, pm = strings.ToUpper(am), strings.ToUpper(pm)
+		if am != l.AM || pm != l.PM {
+			constant = "ampmUpper"
+		}
+	}
+
+	t := nameTable{
+		constant: constant,
+		text:     am + pm,
+		indices:  indices,
+		offsets:  make([]int, 0, 36),
+		longest:  len(am),
+	}
+	if len(pm) > t.longest {
+		t.longest = len(pm)
+	}
+	for _, offset := range []int{0, len(am), len(am) + len(pm)} {
+		for i := 0; i < 12; i++ {
+			t.offsets = append(t.offsets, offset)
+		}
+	}
+	if len(am) == len(pm) {
+		t.width = len(am)
+		t.offsets = t.offsets[:24]
+	}
+
+	if constant != "ampmc" {
+		if asis := l.ampm(false, false); !equalOffsets(t.offsets, asis.offsets) {
+			t.indices = constant + "Index"
+		}
+	}
+	return t
+}
@@ -0,0 +1,124 @@
+package sftgen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupLocale(t *testing.T) {
+	l, err := LookupLocale("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := l.MonthsLong[2], "März"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	// Changing a returned locale changes neither the built-in locale nor the
+	// locale of a generator that was given it.
+	l.MonthsLong[2] = "Maerz"
+	if l, err = LookupLocale("de"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := l.MonthsLong[2], "März"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+	cg, err := NewCodeGenerator("%B", &Config{Locale: l})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.MonthsLong[2] = "Maerz"
+	if got, want := cg.String(), "März"; !strings.Contains(got, want) {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	_, err = LookupLocale("xx")
+	if got, want := err, `cannot find locale "xx"; built-in locales are de, en, es, fr`; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+}
+
+func TestParseLocale(t *testing.T) {
+	const nl = `{
+    "name": "nl",
+    "weekdaysLong": ["zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"],
+    "weekdaysShort": ["zo", "ma", "di", "wo", "do", "vr", "za"],
+    "monthsLong": ["januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"],
+    "monthsShort": ["jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"],
+    "am": "a.m.",
+    "pm": "p.m."
+}`
+
+	t.Run("valid", func(t *testing.T) {
+		l, err := ParseLocale(strings.NewReader(nl))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := l.WeekdaysShort[3], "wo"; got != want {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		_, err := ParseLocale(strings.NewReader(strings.Replace(nl, `"mrt"`, `""`, 1)))
+		if got, want := err, `cannot use locale "nl" without names for March`; got == nil || got.Error() != want {
+			t.Errorf("GOT: %v; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("same indicators", func(t *testing.T) {
+		_, err := ParseLocale(strings.NewReader(strings.Replace(nl, `"p.m."`, `"A.M."`, 1)))
+		if got, want := err, `cannot use locale "nl" without distinct AM and PM strings`; got == nil || got.Error() != want {
+			t.Errorf("GOT: %v; WANT: %q", got, want)
+		}
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := ParseLocale(strings.NewReader(`{"name": "nl", "days": []}`))
+		if err == nil {
+			t.Errorf("GOT: %v; WANT: %v", err, "error")
+		}
+	})
+}
+
+func TestNewCodeGeneratorLocale(t *testing.T) {
+	cg, err := NewCodeGenerator("%a %d %b %p", &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+	for _, want := range []string{
+		"const weekdaysLong = \"SundayMondayTuesdayWednesdayThursdayFridaySaturday\"\n",
+		"var monthsLongIndices = []int{0, 7, 15, 20, 25, 28, 32, 36, 42, 51, 58, 66, 74}\n",
+		"var ampmIndex = []int{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2}\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	de, err := LookupLocale("de")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cg, err = NewCodeGenerator("%A %d %B %b", &Config{Locale: de, ParseFuncName: "parseTime"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got = cg.String()
+	for _, want := range []string{
+		"const weekdaysLong = \"SonntagMontagDienstagMittwochDonnerstagFreitagSamstag\"\n",
+		"const monthsLong = \"JanuarFebruarMärzAprilMaiJuniJuliAugustSeptemberOktoberNovemberDezember\"\n",
+		"const monthsShort = \"JanFebMärAprMaiJunJulAugSepOktNovDez\"\n",
+		"var monthsShortIndices = []int{0, 3, 6, 10, 13, 16, 19, 22, 25, 28, 31, 34, 37}\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+
+	_, err = NewCodeGenerator("Mon Jan 2", &Config{Layout: true, Locale: de})
+	if got, want := err, "cannot use locale with Go reference layouts"; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+}
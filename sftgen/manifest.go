@@ -0,0 +1,89 @@
+package sftgen
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseManifest reads a manifest that maps function names to time format
+// specs, and returns the functions in the order they appear.
+//
+// A manifest is either a JSON object whose keys are function names and whose
+// values are specs, or a sequence of lines, each holding a function name, some
+// white space, and a spec. In the line format, blank lines and lines whose
+// first non white space character is '#' are ignored, and white space
+// surrounding the spec is removed.
+//
+//	# name       spec
+//	formatDate   %F
+//	formatStamp  %F %T
+func ParseManifest(r io.Reader) ([]Function, error) {
+	blob, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if trimmed := bytes.TrimSpace(blob); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseJSONManifest(trimmed)
+	}
+	return parseLineManifest(blob)
+}
+
+func parseLineManifest(blob []byte) ([]Function, error) {
+	var functions []Function
+
+	scanner := bufio.NewScanner(bytes.NewReader(blob))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		i := strings.IndexAny(text, " \t")
+		if i == -1 {
+			return nil, fmt.Errorf("cannot find spec for %q on manifest line %d", text, line)
+		}
+		functions = append(functions, Function{
+			Name: text[:i],
+			Spec: strings.TrimSpace(text[i:]),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return functions, nil
+}
+
+func parseJSONManifest(blob []byte) ([]Function, error) {
+	var functions []Function
+
+	// Decode tokens rather than into a map, in order to preserve the order
+	// of the functions.
+	dec := json.NewDecoder(bytes.NewReader(blob))
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("cannot parse manifest: %w", err)
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse manifest: %w", err)
+		}
+		name := tok.(string) // object keys are always strings
+		var spec string
+		if err = dec.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("cannot parse spec for %q in manifest: %w", name, err)
+		}
+		functions = append(functions, Function{Name: name, Spec: spec})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("cannot parse manifest: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("cannot parse manifest: extra data after object")
+	}
+
+	return functions, nil
+}
@@ -0,0 +1,118 @@
+package sftgen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	want := []Function{
+		{Name: "formatDate", Spec: "%F"},
+		{Name: "formatStamp", Spec: "%F %T"},
+	}
+
+	t.Run("lines", func(t *testing.T) {
+		got, err := ParseManifest(strings.NewReader("# name spec\n\nformatDate %F\n  formatStamp\t%F %T  \n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		got, err := ParseManifest(strings.NewReader(`{"formatDate": "%F", "formatStamp": "%F %T"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("GOT: %v; WANT: %v", got, want)
+		}
+	})
+
+	t.Run("missing spec", func(t *testing.T) {
+		_, err := ParseManifest(strings.NewReader("formatDate %F\nformatStamp\n"))
+		if got, want := err, `cannot find spec for "formatStamp" on manifest line 2`; got == nil || got.Error() != want {
+			t.Errorf("GOT: %v; WANT: %q", got, want)
+		}
+	})
+}
+
+func TestNewManifestCodeGenerator(t *testing.T) {
+	cg, err := NewManifestCodeGenerator([]Function{
+		{Name: "formatDate", Spec: "%a %F"},
+		{Name: "formatStamp", Spec: "%a %F %T"},
+	}, &Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := cg.String()
+
+	for _, want := range []string{
+		"func formatDate(buf []byte, t time.Time) []byte {\n",
+		"func formatStamp(buf []byte, t time.Time) []byte {\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("GOT: %q; WANT: %q", got, want)
+		}
+	}
+	for _, table := range []string{"const sftDigits", "const sftWeekdaysLong", "var sftWeekdaysLongIndices"} {
+		if got, want := strings.Count(got, table), 1; got != want {
+			t.Errorf("%s: GOT: %v; WANT: %v", table, got, want)
+		}
+		if got, want := strings.Index(got, table), strings.Index(got, "func formatDate"); got > want {
+			t.Errorf("%s: GOT: declared in function; WANT: declared at package level", table)
+		}
+	}
+
+	// The hoisted tables do not collide with the names of the package.
+	output := runProgram(t, map[string][]byte{
+		"formats.go": cg.Bytes(),
+		"main.go": []byte(`package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const digits = "declared by the package"
+
+var weekdaysLong = []string{"declared", "by", "the", "package"}
+
+func main() {
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	fmt.Println(string(formatDate(nil, when)))
+	fmt.Println(string(formatStamp(nil, when)))
+	fmt.Println(digits, weekdaysLong)
+}
+`),
+	})
+	if got, want := string(output), "Mon 2006-01-02\nMon 2006-01-02 15:04:05\ndeclared by the package [declared by the package]\n"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	_, err = NewManifestCodeGenerator([]Function{
+		{Name: "formatDate", Spec: "%F"},
+		{Name: "formatDate", Spec: "%T"},
+	}, nil)
+	if got, want := err, `cannot use "formatDate" as function name more than once`; got == nil || got.Error() != want {
+		t.Errorf("GOT: %v; WANT: %q", got, want)
+	}
+
+	for _, c := range []struct {
+		config Config
+		want   string
+	}{
+		{Config{ParseFuncName: "parseTime"}, "cannot emit parse function for manifests"},
+		{Config{CacheType: "TimeFormatter"}, "cannot use cache with manifests"},
+		{Config{FormatterType: "Stamp"}, "cannot emit formatter type for manifests"},
+	} {
+		config := c.config
+		_, err = NewManifestCodeGenerator([]Function{{Name: "formatDate", Spec: "%F"}}, &config)
+		if got, want := err, c.want; got == nil || got.Error() != want {
+			t.Errorf("GOT: %v; WANT: %q", got, want)
+		}
+	}
+}
@@ -0,0 +1,1045 @@
+package sftgen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// scanParse walks the same directives as scan, but builds the operations
+// required to parse a byte slice formatted according to the spec back into a
+// time.Time value.
+func (cg *CodeGenerator) scanParse() (code, error) {
+	var dest code
+
+	for i, d := range cg.directives {
+		if d.verb == 0 {
+			dest = join(dest, cg.readStringConstant(d.literal))
+			continue
+		}
+		upper := d.upcase || d.swapcase
+
+		if fill := d.fieldFill(); fill != 0 {
+			dest = join(dest, cg.readFieldPad(d.width, fill))
+		}
+		switch d.verb {
+		case 'a':
+			dest = join(dest, cg.readWeekdayShort(upper))
+		case 'A':
+			dest = join(dest, cg.readWeekdayLong(upper))
+		case 'b':
+			dest = join(dest, cg.readMonthShort(upper))
+		case 'B':
+			dest = join(dest, cg.readMonthLong(upper))
+		case 'c':
+			dest = join(dest, cg.readC(d.upcase, cg.wideYear(i)))
+		case 'C':
+			dest = join(dest, cg.readCC(d.padding('0'), d.fieldWidth(2), cg.wideYear(i)))
+		case 'd':
+			dest = join(dest, cg.readD(d.padding('0'), d.fieldWidth(2)))
+		case 'D':
+			dest = join(dest, cg.readDC())
+		case 'e':
+			dest = join(dest, cg.readE(d.padding('_'), d.fieldWidth(2)))
+		case 'F':
+			dest = join(dest, cg.readFC())
+		case 'h':
+			dest = join(dest, cg.readMonthShort(upper))
+		case 'H':
+			dest = join(dest, cg.readHC(d.padding('0'), d.fieldWidth(2)))
+		case 'I':
+			dest = join(dest, cg.readIC(d.padding('0'), d.fieldWidth(2)))
+		case 'j':
+			dest = join(dest, cg.readJ(d.padding('0'), d.fieldWidth(3)))
+		case 'k':
+			dest = join(dest, cg.readK(d.padding('_'), d.fieldWidth(2)))
+		case 'l':
+			dest = join(dest, cg.readL(d.padding('_'), d.fieldWidth(2)))
+		case 'm':
+			dest = join(dest, cg.readM(d.padding('0'), d.fieldWidth(2)))
+		case 'M':
+			dest = join(dest, cg.readMC(d.padding('0'), d.fieldWidth(2)))
+		case 'n':
+			dest = join(dest, cg.readStringConstant("\n"))
+		case 'N':
+			dest = join(dest, cg.readNC(readPad(d.pad), d.fractionDigits()), cg.readStringConstant(d.fractionPadding()))
+		case 'p':
+			if d.swapcase {
+				dest = join(dest, cg.readPC())
+			} else {
+				dest = join(dest, cg.readP(d.upcase))
+			}
+		case 'P':
+			dest = join(dest, cg.readPC())
+		case 'r':
+			dest = join(dest, cg.readR())
+		case 'R':
+			dest = join(dest, cg.readRC())
+		case 's':
+			dest = join(dest, cg.readS())
+		case 'S':
+			dest = join(dest, cg.readSC(d.padding('0'), d.fieldWidth(2)))
+		case 't':
+			dest = join(dest, cg.readStringConstant("\t"))
+		case 'T':
+			dest = join(dest, cg.readTC())
+		case 'u':
+			dest = join(dest, cg.readU())
+		case 'w':
+			dest = join(dest, cg.readW())
+		case 'x':
+			dest = join(dest, cg.readDC())
+		case 'X':
+			dest = join(dest, cg.readTC())
+		case 'y':
+			dest = join(dest, cg.readY(d.padding('0'), d.fieldWidth(2)))
+		case 'Y':
+			dest = join(dest, cg.readYC(d.padding('0'), d.yearWidth(), d.width > 0, cg.wideYear(i)))
+		case 'z':
+			if d.width > 0 || d.pad == '-' || d.pad == '_' {
+				dest = join(dest, cg.readZField(d.colons, d.width, d.pad))
+			} else {
+				dest = join(dest, cg.readZ(d.colons))
+			}
+		case 'Z':
+			dest = join(dest, cg.readZC())
+		case '%':
+			dest = join(dest, cg.readStringConstant("%"))
+		case '+':
+			dest = join(dest, cg.readPlus(d.upcase, cg.wideYear(i)))
+		case '1':
+			dest = join(dest, cg.readTZ())
+		case '2':
+			dest = join(dest, cg.readLMin())
+		case '3':
+			dest = join(dest, cg.readMilli())
+		case '4':
+			dest = join(dest, cg.readMicro())
+		case verbLayoutZone:
+			dest = join(dest, cg.readZC())
+		case verbLayoutOffset:
+			dest = join(dest, cg.readLayoutOffset(d.zulu, d.colon, d.precision))
+		case verbLayoutFraction:
+			dest = join(dest, cg.readFraction(d.separator, d.width, d.trim))
+		case 'g', 'G', 'U', 'V', 'W':
+			// The week numbers and the ISO 8601 week-based year cannot be
+			// converted back to a calendar date without the weekday, which
+			// the parser ignores.
+			return nil, fmt.Errorf("cannot generate parser for format verb %q at index %d", d.verb, d.index)
+		default:
+			return nil, fmt.Errorf("cannot recognize format verb %q at index %d", d.verb, d.index)
+		}
+	}
+
+	return dest, nil
+}
+
+// prepareParse returns the source code of the parse function, built around
+// the operations created by scanParse. When hoisted is true, the lookup tables
+// are expected to be declared at package level.
+func (cg *CodeGenerator) prepareParse(source code, hoisted bool) ([]byte, error) {
+	var body code
+
+	if !hoisted {
+		body = declareTables(cg.parseTables)
+	}
+
+	body = join(body,
+		varDecl(ident("int"), "year", "hour", "minute", "second", "nanosecond", "offset"),
+		assignList(idents("month", "day"), token.DEFINE, []ast.Expr{intLit(1), intLit(1)}))
+	if cg.parseCentury {
+		body = join(body, varDecl(ident("int"), "century"), varDecl(ident("bool"), "negativeCentury"))
+	}
+	if cg.parseYearInCentury {
+		body = join(body, varDecl(ident("int"), "yearInCentury"))
+	}
+	if cg.parseYearDay {
+		body = join(body, varDecl(ident("int"), "yearday"))
+	}
+	if cg.parsePM {
+		body = join(body, varDecl(ident("bool"), "pm"))
+	}
+	if cg.parseEpoch {
+		body = join(body, varDecl(ident("int64"), "epoch"), varDecl(ident("bool"), "negative"))
+	}
+	if cg.parseZoneOffset {
+		body = join(body, varDecl(ident("int"), "zoneOffset"))
+	}
+	if cg.parseZoneName {
+		body = join(body, varDecl(arrayOf(nil, ident("byte")), "zoneName"))
+	}
+
+	body = join(body, source, note(""), ifElse(binary(ident("offset"), token.LSS, lenOf("b")),
+		parseError("extra text at index %d", ident("offset")), nil))
+
+	// Reconcile fields whose meaning depends on other fields.
+	addNoon := assign(ident("hour"), token.ADD_ASSIGN, intLit(12))
+	if cg.parse12Hour {
+		body = join(body, note(""), assign(ident("hour"), token.REM_ASSIGN, intLit(12)))
+		if cg.parsePM {
+			body = join(body, ifElse(ident("pm"), addNoon, nil))
+		}
+	} else if cg.parsePM {
+		body = join(body, note(""), ifElse(and(ident("pm"), binary(ident("hour"), token.LSS, intLit(12))), addNoon, nil))
+	}
+	negateYear := ifElse(ident("negativeCentury"), assign(ident("year"), token.ASSIGN, unary(token.SUB, ident("year"))), nil)
+	switch {
+	case cg.parseCentury && cg.parseYearInCentury:
+		body = join(body, note(""), assign(ident("year"), token.ASSIGN,
+			binary(binary(ident("century"), token.MUL, intLit(100)), token.ADD, ident("yearInCentury"))), negateYear)
+	case cg.parseCentury:
+		body = join(body, note(""), assign(ident("year"), token.ASSIGN, binary(ident("century"), token.MUL, intLit(100))), negateYear)
+	case cg.parseYearInCentury:
+		// POSIX: values 69 through 99 refer to the twentieth century, and 00
+		// through 68 refer to the twenty-first century.
+		body = join(body, note(""), ifElse(binary(ident("yearInCentury"), token.LSS, intLit(69)),
+			assign(ident("year"), token.ASSIGN, binary(intLit(2000), token.ADD, ident("yearInCentury"))),
+			assign(ident("year"), token.ASSIGN, binary(intLit(1900), token.ADD, ident("yearInCentury")))))
+	}
+	if cg.parseYearDay {
+		if cg.parseMonthDay {
+			// The month and day of month take precedence.
+			body = join(body, note(""), assign(ident("_"), token.ASSIGN, ident("yearday")))
+		} else {
+			// Without a month and day of month, time.Date normalizes the day
+			// of year as a day of January.
+			body = join(body, note(""), assign(ident("day"), token.ASSIGN, ident("yearday")))
+		}
+	}
+	if cg.parseMonthDay {
+		lastDay := method(call("time.Date", ident("year"), binary(call("time.Month", ident("month")), token.ADD, intLit(1)),
+			intLit(0), intLit(0), intLit(0), intLit(0), intLit(0), sel("time.UTC")), "Day")
+		body = join(body, note(""), ifElse(and(binary(ident("day"), token.GTR, intLit(28)), binary(ident("day"), token.GTR, lastDay)),
+			parseError("day out of range"), nil))
+	}
+
+	// Determine the location of the parsed time.
+	body = join(body, note(""), define("loc", sel("time.UTC")))
+	switch {
+	case cg.parseZoneLocation:
+		if cg.parseZoneOffset {
+			// The location determines the zone offset.
+			body = join(body, assign(ident("_"), token.ASSIGN, ident("zoneOffset")))
+		}
+		body = join(body,
+			assignList(idents("location", "err"), token.DEFINE, []ast.Expr{call("time.LoadLocation", call("string", ident("zoneName")))}),
+			ifElse(binary(ident("err"), token.NEQ, ident("nil")), parseError("%w", ident("err")), nil),
+			assign(ident("loc"), token.ASSIGN, ident("location")))
+	case cg.parseZoneOffset && cg.parseZoneName:
+		body = join(body, ifElse(binary(ident("zoneOffset"), token.NEQ, intLit(0)),
+			assign(ident("loc"), token.ASSIGN, call("time.FixedZone", call("string", ident("zoneName")), ident("zoneOffset"))), nil))
+	case cg.parseZoneOffset:
+		body = join(body, ifElse(binary(ident("zoneOffset"), token.NEQ, intLit(0)),
+			assign(ident("loc"), token.ASSIGN, call("time.FixedZone", strLit(""), ident("zoneOffset"))), nil))
+	case cg.parseZoneName && cg.parseEpoch:
+		// Seconds since the epoch do not depend on the zone.
+		body = join(body, assign(ident("_"), token.ASSIGN, ident("zoneName")))
+	case cg.parseZoneName:
+		// Like time.Parse, use the local zone when it has the same
+		// abbreviation, and otherwise fabricate a location with the given
+		// abbreviation and a zero offset.
+		zoneName := func() ast.Expr { return call("string", ident("zoneName")) }
+		body = join(body, switchStmt(zoneName(),
+			caseClause([]ast.Expr{strLit("UTC"), strLit("GMT")}, nil),
+			caseClause(nil, join(
+				define("t", call("time.Date", ident("year"), call("time.Month", ident("month")), ident("day"),
+					ident("hour"), ident("minute"), ident("second"), ident("nanosecond"), sel("time.Local"))),
+				ifInit(assignList(idents("name", "_"), token.DEFINE, []ast.Expr{call("t.Zone")}), binary(ident("name"), token.EQL, zoneName()),
+					returnStmt(ident("t"), ident("nil")), nil),
+				assign(ident("loc"), token.ASSIGN, call("time.FixedZone", zoneName(), intLit(0)))))))
+	}
+
+	if cg.parseEpoch {
+		// Seconds since the epoch take precedence over all other fields.
+		body = join(body, note(""),
+			assignList(idents("_", "_", "_", "_", "_", "_"), token.ASSIGN, idents("year", "month", "day", "hour", "minute", "second")),
+			ifElse(ident("negative"), assign(ident("epoch"), token.ASSIGN, unary(token.SUB, ident("epoch"))), nil),
+			returnStmt(method(call("time.Unix", ident("epoch"), call("int64", ident("nanosecond"))), "In", ident("loc")), ident("nil")))
+	} else {
+		body = join(body, returnStmt(call("time.Date", ident("year"), call("time.Month", ident("month")), ident("day"),
+			ident("hour"), ident("minute"), ident("second"), ident("nanosecond"), ident("loc")), ident("nil")))
+	}
+
+	if hoisted {
+		hoistTables(body, cg.parseTables)
+	}
+	params := []*ast.Field{field("b", arrayOf(nil, ident("byte")))}
+	results := []*ast.Field{field("", sel("time.Time")), field("", ident("error"))}
+	return printFunction(funcDecl(cg.parseFunctionName, params, results, body), cg.annotate)
+}
+
+// parseError returns the statement that returns the error that b cannot be
+// parsed, described by format and args like fmt.Errorf describes them.
+func parseError(format string, args ...ast.Expr) code {
+	args = append([]ast.Expr{strLit("cannot parse %q: " + format), ident("b")}, args...)
+	return returnStmt(compositeLit(sel("time.Time")), call("fmt.Errorf", args...))
+}
+
+// byteAt returns the byte of b at index i.
+func byteAt(i ast.Expr) ast.Expr {
+	return indexExpr(ident("b"), i)
+}
+
+// inRange returns the condition that the byte x is between lo and hi.
+func inRange(lo byte, x ast.Expr, hi byte) ast.Expr {
+	return and(binary(charLit(lo), token.LEQ, x), binary(x, token.LEQ, charLit(hi)))
+}
+
+// outOfRange returns the condition that the byte x is not between lo and hi.
+func outOfRange(x ast.Expr, lo, hi byte) ast.Expr {
+	return or(binary(x, token.LSS, charLit(lo)), binary(x, token.GTR, charLit(hi)))
+}
+
+// atEnd returns the condition that offset is at the end of b.
+func atEnd() ast.Expr {
+	return binary(ident("offset"), token.EQL, lenOf("b"))
+}
+
+// notAtEnd returns the condition that offset is before the end of b.
+func notAtEnd() ast.Expr {
+	return binary(ident("offset"), token.LSS, lenOf("b"))
+}
+
+// fewer returns the condition that b has fewer than n bytes after offset.
+func fewer(n int) ast.Expr {
+	return binary(lenOf("b"), token.LSS, plus("offset", n))
+}
+
+// prefixAt returns the condition that b has the prefix s after offset.
+func prefixAt(s string) ast.Expr {
+	return binary(call("string", sliceExpr(ident("b"), ident("offset"), plus("offset", len(s)))), token.EQL, strLit(s))
+}
+
+// accumulate returns the statement that appends the decimal digit c to the
+// value of variable, after converting the digit to the type conversion.
+func accumulate(variable, conversion string, c ast.Expr) code {
+	return assign(ident(variable), token.ASSIGN, binary(binary(ident(variable), token.MUL, intLit(10)),
+		token.ADD, call(conversion, binary(c, token.SUB, charLit('0')))))
+}
+
+// digitValue returns the value of the decimal digit c.
+func digitValue(c ast.Expr) ast.Expr {
+	return call("int", binary(c, token.SUB, charLit('0')))
+}
+
+// readDigits returns the code that reads an integer of up to width digits
+// into variable, and ensures it is within min and max. When pad is '0' or ' '
+// the field is exactly width bytes wide, and may start with that padding.
+// When pad is 0 the field is between one and width digits wide.
+func (cg *CodeGenerator) readDigits(variable string, width int, pad byte, min, max int) code {
+	var foo code
+
+	outside := func(start string) code {
+		return ifElse(or(binary(ident(variable), token.LSS, intLit(min)), binary(ident(variable), token.GTR, intLit(max))),
+			parseError(variable+" out of range at index %d", ident(start)), nil)
+	}
+	digit := func() code {
+		return ifElse(outOfRange(ident("c"), '0', '9'), parseError("expected digit at index %d", ident("i")), nil)
+	}
+
+	switch pad {
+	case 0:
+		return join(note("readDigits variable width"),
+			ifElse(or(atEnd(), outOfRange(byteAt(ident("offset")), '0', '9')), parseError("expected digit at index %d", ident("offset")), nil),
+			block(
+				define("start", ident("offset")),
+				assign(ident(variable), token.ASSIGN, intLit(0)),
+				forStmt(nil, and(notAtEnd(), binary(binary(ident("offset"), token.SUB, ident("start")), token.LSS, intLit(width)), inRange('0', byteAt(ident("offset")), '9')),
+					incDec(ident("offset"), token.INC),
+					accumulate(variable, "int", byteAt(ident("offset")))),
+				outside("start")))
+	case ' ':
+		foo = join(note("readDigits space padded"),
+			ifElse(fewer(width), parseError(fmt.Sprintf("expected %d digits at index %%d", width), ident("offset")), nil),
+			assign(ident(variable), token.ASSIGN, intLit(0)),
+			forStmt(define("i", ident("offset")), binary(ident("i"), token.LSS, plus("offset", width)), incDec(ident("i"), token.INC), join(
+				define("c", byteAt(ident("i"))),
+				ifElse(and(binary(ident("c"), token.EQL, charLit(' ')), binary(ident("i"), token.LSS, plus("offset", width-1)),
+					paren(or(binary(ident("i"), token.EQL, ident("offset")), binary(byteAt(plus("i", -1)), token.EQL, charLit(' '))))),
+					continueStmt(), nil),
+				digit(),
+				accumulate(variable, "int", ident("c")))))
+	default:
+		foo = join(note("readDigits zero padded"),
+			ifElse(fewer(width), parseError(fmt.Sprintf("expected %d digits at index %%d", width), ident("offset")), nil),
+			assign(ident(variable), token.ASSIGN, intLit(0)),
+			forStmt(define("i", ident("offset")), binary(ident("i"), token.LSS, plus("offset", width)), incDec(ident("i"), token.INC), join(
+				define("c", byteAt(ident("i"))),
+				digit(),
+				accumulate(variable, "int", ident("c")))))
+	}
+
+	return join(foo, outside("offset"), assign(ident("offset"), token.ADD_ASSIGN, intLit(width)))
+}
+
+// readFieldPad returns the code that skips the fill bytes that pad a field
+// on the left to width bytes. It never skips the final byte of the field.
+func (cg *CodeGenerator) readFieldPad(width int, fill byte) code {
+	return join(note("readFieldPad"), forStmt(define("start", ident("offset")),
+		and(notAtEnd(), binary(binary(ident("offset"), token.SUB, ident("start")), token.LSS, intLit(width-1)), binary(byteAt(ident("offset")), token.EQL, charLit(fill))),
+		incDec(ident("offset"), token.INC), nil))
+}
+
+func (cg *CodeGenerator) readStringConstant(someString string) code {
+	ls := len(someString)
+	if ls == 0 {
+		return nil
+	}
+	if ls == 1 {
+		return join(section("readStringConstant"),
+			ifElse(or(atEnd(), binary(byteAt(ident("offset")), token.NEQ, charLit(someString[0]))),
+				parseError("expected %q at index %d", strLit(someString), ident("offset")), nil),
+			incDec(ident("offset"), token.INC))
+	}
+	return join(section("readStringConstant"),
+		ifElse(or(fewer(ls), binary(call("string", sliceExpr(ident("b"), ident("offset"), plus("offset", ls))), token.NEQ, strLit(someString))),
+			parseError("expected %q at index %d", strLit(someString), ident("offset")), nil),
+		assign(ident("offset"), token.ADD_ASSIGN, intLit(ls)))
+}
+
+// readName returns the code that matches the longest of the names in the
+// table t, and then runs store, which stores the zero based index i of the
+// matched name.
+func (cg *CodeGenerator) readName(store code, t nameTable, count int, what string) code {
+	cg.parseTables.declare(t)
+	right := indexExpr(ident(t.indices), binary(ident("j"), token.ADD, intLit(1)))
+	if t.width > 0 {
+		right = binary(indexExpr(ident(t.indices), ident("j")), token.ADD, intLit(t.width))
+	}
+	name := sliceExpr(ident("b"), ident("offset"), binary(ident("offset"), token.ADD, lenOf("name")))
+	return block(
+		assignList(idents("i", "length"), token.DEFINE, []ast.Expr{intLit(-1), intLit(0)}),
+		forStmt(define("j", intLit(0)), binary(ident("j"), token.LSS, intLit(count)), incDec(ident("j"), token.INC), join(
+			define("name", sliceExpr(ident(t.constant), indexExpr(ident(t.indices), ident("j")), right)),
+			ifElse(and(binary(lenOf("name"), token.GTR, ident("length")), binary(binary(lenOf("b"), token.SUB, ident("offset")), token.GEQ, lenOf("name")),
+				binary(call("string", name), token.EQL, ident("name"))),
+				assignList(idents("i", "length"), token.ASSIGN, []ast.Expr{ident("j"), lenOf("name")}), nil))),
+		ifElse(binary(ident("i"), token.LSS, intLit(0)), parseError("expected "+what+" name at index %d", ident("offset")), nil),
+		store,
+		assign(ident("offset"), token.ADD_ASSIGN, ident("length")))
+}
+
+// ignoreIndex stores the index of a matched name nowhere, for the names
+// whose fields the parser ignores.
+func ignoreIndex() code {
+	return assign(ident("_"), token.ASSIGN, ident("i"))
+}
+
+// storeMonth stores the index of a matched month name as its month number.
+func storeMonth() code {
+	return assign(ident("month"), token.ASSIGN, plus("i", 1))
+}
+
+func (cg *CodeGenerator) readWeekdayShort(upper bool) code {
+	return join(section("readWeekdayShort"), cg.readName(ignoreIndex(), cg.locale.weekdaysShort(upper), 7, "weekday"))
+}
+
+func (cg *CodeGenerator) readWeekdayLong(upper bool) code {
+	return join(section("readWeekdayLong"), cg.readName(ignoreIndex(), cg.locale.weekdaysLong(upper), 7, "weekday"))
+}
+
+func (cg *CodeGenerator) readMonthShort(upper bool) code {
+	cg.parseMonthDay = true
+	return join(section("readMonthShort"), cg.readName(storeMonth(), cg.locale.monthsShort(upper), 12, "month"))
+}
+
+func (cg *CodeGenerator) readMonthLong(upper bool) code {
+	cg.parseMonthDay = true
+	return join(section("readMonthLong"), cg.readName(storeMonth(), cg.locale.monthsLong(upper), 12, "month"))
+}
+
+func (cg *CodeGenerator) readC(upper, wide bool) code {
+	return join(
+		section("readC"),
+		cg.readWeekdayShort(upper),
+		cg.readStringConstant(" "),
+		cg.readMonthShort(upper),
+		cg.readStringConstant(" "),
+		cg.readE('_', 2),
+		cg.readStringConstant(" "),
+		cg.readTC(),
+		cg.readStringConstant(" "),
+		cg.readYC('0', 4, false, wide),
+	)
+}
+
+func (cg *CodeGenerator) readCC(pad byte, width int, wide bool) code {
+	cg.parseCentury = true
+	negate := assign(ident("negativeCentury"), token.ASSIGN, ident("true"))
+	return join(section("readCC"), cg.readYearDigits("century", negate, width, readPad(pad), true, wide))
+}
+
+func (cg *CodeGenerator) readD(pad byte, width int) code {
+	cg.parseMonthDay = true
+	return join(section("readD"), cg.readDigits("day", width, readPad(pad), 1, 31))
+}
+
+func (cg *CodeGenerator) readDC() code {
+	return join(
+		section("readDC"),
+		cg.readM('0', 2),
+		cg.readStringConstant("/"),
+		cg.readD('0', 2),
+		cg.readStringConstant("/"),
+		cg.readY('0', 2),
+	)
+}
+
+func (cg *CodeGenerator) readE(pad byte, width int) code {
+	cg.parseMonthDay = true
+	return join(section("readE"), cg.readDigits("day", width, readPad(pad), 1, 31))
+}
+
+func (cg *CodeGenerator) readFC() code {
+	return join(
+		section("readFC"),
+		cg.readYC('0', 4, false, true),
+		cg.readStringConstant("-"),
+		cg.readM('0', 2),
+		cg.readStringConstant("-"),
+		cg.readD('0', 2),
+	)
+}
+
+func (cg *CodeGenerator) readHC(pad byte, width int) code {
+	return join(section("readHC"), cg.readDigits("hour", width, readPad(pad), 0, 23))
+}
+
+func (cg *CodeGenerator) readIC(pad byte, width int) code {
+	// Accept zero as well as 12 for the twelfth hour, as some programs
+	// write midnight and noon as 00.
+	cg.parse12Hour = true
+	return join(section("readIC"), cg.readDigits("hour", width, readPad(pad), 0, 12))
+}
+
+func (cg *CodeGenerator) readJ(pad byte, width int) code {
+	cg.parseYearDay = true
+	return join(section("readJ"), cg.readDigits("yearday", width, readPad(pad), 1, 366))
+}
+
+func (cg *CodeGenerator) readK(pad byte, width int) code {
+	return join(section("readK"), cg.readDigits("hour", width, readPad(pad), 0, 23))
+}
+
+func (cg *CodeGenerator) readL(pad byte, width int) code {
+	cg.parse12Hour = true
+	return join(section("readL"), cg.readDigits("hour", width, readPad(pad), 0, 12))
+}
+
+func (cg *CodeGenerator) readLMin() code {
+	cg.parse12Hour = true
+	return join(section("readLMin"), cg.readDigits("hour", 2, 0, 0, 12))
+}
+
+func (cg *CodeGenerator) readM(pad byte, width int) code {
+	cg.parseMonthDay = true
+	return join(section("readM"), cg.readDigits("month", width, readPad(pad), 1, 12))
+}
+
+func (cg *CodeGenerator) readMC(pad byte, width int) code {
+	return join(section("readMC"), cg.readDigits("minute", width, readPad(pad), 0, 59))
+}
+
+// readNC returns the code that reads a fractional second of width digits
+// written by writeFractionField. When pad is 0 its trailing zeros may have been
+// removed, and when pad is ' ' they may have been replaced with spaces.
+func (cg *CodeGenerator) readNC(pad byte, width int) code {
+	if width == 9 && pad == '0' {
+		return join(section("readNC"), cg.readDigits("nanosecond", 9, '0', 0, 999999999))
+	}
+	max, multiplier := 9, 1
+	for i := 1; i < width; i++ {
+		max = max*10 + 9
+	}
+	for i := width; i < 9; i++ {
+		multiplier *= 10
+	}
+
+	switch pad {
+	case 0:
+		// Each digit that was removed multiplies the value by ten.
+		return join(section("readNC"), block(
+			define("first", ident("offset")),
+			cg.readDigits("nanosecond", width, 0, 0, max),
+			forStmt(define("i", binary(ident("offset"), token.SUB, ident("first"))), binary(ident("i"), token.LSS, intLit(9)), incDec(ident("i"), token.INC),
+				assign(ident("nanosecond"), token.MUL_ASSIGN, intLit(10)))))
+	case ' ':
+		// A space after the first digit is a zero when only spaces follow
+		// it.
+		last := plus("offset", width-1)
+		foo := join(section("readNC"),
+			ifElse(fewer(width), parseError(fmt.Sprintf("expected %d digits at index %%d", width), ident("offset")), nil),
+			assign(ident("nanosecond"), token.ASSIGN, intLit(0)),
+			forStmt(define("i", ident("offset")), binary(ident("i"), token.LSS, plus("offset", width)), incDec(ident("i"), token.INC), join(
+				define("c", byteAt(ident("i"))),
+				ifElse(and(binary(ident("c"), token.EQL, charLit(' ')), binary(ident("i"), token.GTR, ident("offset")),
+					paren(or(binary(ident("i"), token.EQL, last), binary(byteAt(plus("i", 1)), token.EQL, charLit(' '))))),
+					assign(ident("c"), token.ASSIGN, charLit('0')), nil),
+				ifElse(outOfRange(ident("c"), '0', '9'), parseError("expected digit at index %d", ident("i")), nil),
+				accumulate("nanosecond", "int", ident("c")))),
+			assign(ident("offset"), token.ADD_ASSIGN, intLit(width)))
+		if multiplier > 1 {
+			foo = join(foo, assign(ident("nanosecond"), token.MUL_ASSIGN, intLit(multiplier)))
+		}
+		return foo
+	}
+	return join(section("readNC"), cg.readDigits("nanosecond", width, '0', 0, max), assign(ident("nanosecond"), token.MUL_ASSIGN, intLit(multiplier)))
+}
+
+func (cg *CodeGenerator) readMicro() code {
+	return join(section("readMicro"), cg.readDigits("nanosecond", 6, '0', 0, 999999), assign(ident("nanosecond"), token.MUL_ASSIGN, intLit(1000)))
+}
+
+func (cg *CodeGenerator) readMilli() code {
+	return join(section("readMilli"), cg.readDigits("nanosecond", 3, '0', 0, 999), assign(ident("nanosecond"), token.MUL_ASSIGN, intLit(1000000)))
+}
+
+func (cg *CodeGenerator) readAMPM(am, pm string) code {
+	cg.parsePM = true
+	expected := func() code {
+		return parseError("expected %q or %q at index %d", strLit(am), strLit(pm), ident("offset"))
+	}
+	if len(am) != len(pm) {
+		// Match the longer indicator first, in case the other is its prefix.
+		first, second, isPM := am, pm, false
+		if len(pm) > len(am) {
+			first, second, isPM = pm, am, true
+		}
+		indicator := func(s string, isPM bool) code {
+			return caseClause([]ast.Expr{and(binary(lenOf("b"), token.GEQ, plus("offset", len(s))), prefixAt(s))}, join(
+				assign(ident("pm"), token.ASSIGN, ident(strconv.FormatBool(isPM))),
+				assign(ident("offset"), token.ADD_ASSIGN, intLit(len(s)))))
+		}
+		return switchStmt(nil, indicator(first, isPM), indicator(second, !isPM), caseClause(nil, expected()))
+	}
+	return join(
+		ifElse(fewer(len(am)), expected(), nil),
+		switchStmt(call("string", sliceExpr(ident("b"), ident("offset"), plus("offset", len(am)))),
+			caseClause([]ast.Expr{strLit(am)}, assign(ident("pm"), token.ASSIGN, ident("false"))),
+			caseClause([]ast.Expr{strLit(pm)}, assign(ident("pm"), token.ASSIGN, ident("true"))),
+			caseClause(nil, expected())),
+		assign(ident("offset"), token.ADD_ASSIGN, intLit(len(am))))
+}
+
+func (cg *CodeGenerator) readP(upper bool) code {
+	am, pm := cg.locale.AM, cg.locale.PM
+	if upper {
+		am, pm = strings.ToUpper(am), strings.ToUpper(pm)
+	}
+	return join(section("readP"), cg.readAMPM(am, pm))
+}
+
+func (cg *CodeGenerator) readPC() code {
+	return join(section("readPC"), cg.readAMPM(strings.ToLower(cg.locale.AM), strings.ToLower(cg.locale.PM)))
+}
+
+func (cg *CodeGenerator) readR() code {
+	return join(
+		section("readR"),
+		cg.readIC('0', 2),
+		cg.readStringConstant(":"),
+		cg.readMC('0', 2),
+		cg.readStringConstant(":"),
+		cg.readSC('0', 2),
+		cg.readStringConstant(" "),
+		cg.readP(false),
+	)
+}
+
+func (cg *CodeGenerator) readRC() code {
+	return join(
+		section("readRC"),
+		cg.readHC('0', 2),
+		cg.readStringConstant(":"),
+		cg.readMC('0', 2),
+	)
+}
+
+func (cg *CodeGenerator) readS() code {
+	cg.parseEpoch = true
+	return join(section("readS"),
+		assign(ident("negative"), token.ASSIGN, and(notAtEnd(), binary(byteAt(ident("offset")), token.EQL, charLit('-')))),
+		ifElse(ident("negative"), incDec(ident("offset"), token.INC), nil),
+		ifElse(or(atEnd(), outOfRange(byteAt(ident("offset")), '0', '9')), parseError("expected digit at index %d", ident("offset")), nil),
+		assign(ident("epoch"), token.ASSIGN, intLit(0)),
+		forStmt(define("start", ident("offset")), and(notAtEnd(), inRange('0', byteAt(ident("offset")), '9')), incDec(ident("offset"), token.INC), join(
+			ifElse(binary(binary(ident("offset"), token.SUB, ident("start")), token.EQL, intLit(18)),
+				parseError("epoch out of range at index %d", ident("start")), nil),
+			accumulate("epoch", "int64", byteAt(ident("offset"))))))
+}
+
+func (cg *CodeGenerator) readSC(pad byte, width int) code {
+	return join(section("readSC"), cg.readDigits("second", width, readPad(pad), 0, 59))
+}
+
+func (cg *CodeGenerator) readTC() code {
+	return join(
+		section("readTC"),
+		cg.readHC('0', 2),
+		cg.readStringConstant(":"),
+		cg.readMC('0', 2),
+		cg.readStringConstant(":"),
+		cg.readSC('0', 2),
+	)
+}
+
+// readWeekdayNumber returns the code that skips a weekday number between lo
+// and hi, which the parser ignores.
+func readWeekdayNumber(lo, hi byte) code {
+	return join(
+		ifElse(or(atEnd(), outOfRange(byteAt(ident("offset")), lo, hi)), parseError("expected weekday number at index %d", ident("offset")), nil),
+		incDec(ident("offset"), token.INC))
+}
+
+func (cg *CodeGenerator) readU() code {
+	return join(section("readU"), readWeekdayNumber('1', '7'))
+}
+
+func (cg *CodeGenerator) readW() code {
+	return join(section("readW"), readWeekdayNumber('0', '6'))
+}
+
+func (cg *CodeGenerator) readY(pad byte, width int) code {
+	cg.parseYearInCentury = true
+	return join(section("readY"), cg.readDigits("yearInCentury", width, readPad(pad), 0, 99))
+}
+
+func (cg *CodeGenerator) readYC(pad byte, width int, signWidth, wide bool) code {
+	negate := assign(ident("year"), token.ASSIGN, unary(token.SUB, ident("year")))
+	return join(section("readYC"), cg.readYearDigits("year", negate, width, readPad(pad), signWidth, wide))
+}
+
+// readYearDigits returns the code that reads a year, or a century, written by
+// writeYearDigits into variable, and runs negate when it has a minus sign.
+// When wide is true, the field may have more than width digits, which
+// requires that it is not followed by a digit. Otherwise it has no more
+// digits than its width, less the minus sign when signWidth is true, and a
+// wider year cannot be read.
+func (cg *CodeGenerator) readYearDigits(variable string, negate code, width int, pad byte, signWidth, wide bool) code {
+	var foo code
+	if pad != 0 {
+		foo = define("start", ident("offset"))
+	}
+	if pad == ' ' {
+		foo = join(foo, forStmt(nil, and(notAtEnd(), binary(binary(ident("offset"), token.SUB, ident("start")), token.LSS, intLit(width-1)), binary(byteAt(ident("offset")), token.EQL, charLit(' '))), nil,
+			incDec(ident("offset"), token.INC)))
+	}
+	foo = join(foo,
+		define("negative", and(notAtEnd(), binary(byteAt(ident("offset")), token.EQL, charLit('-')))),
+		ifElse(ident("negative"), incDec(ident("offset"), token.INC), nil),
+		define("digits", ident("offset")))
+
+	// The field ends at end, and padding requires it to extend to at least
+	// the end of the padded field.
+	end, short := plus("digits", width), binary(ident("offset"), token.EQL, ident("digits"))
+	if pad != 0 {
+		end = plus("start", width)
+		if !signWidth {
+			foo = join(foo, define("fieldWidth", intLit(width)), ifElse(ident("negative"), incDec(ident("fieldWidth"), token.INC), nil))
+			end = binary(ident("start"), token.ADD, ident("fieldWidth"))
+		}
+		short = or(short, binary(ident("offset"), token.LSS, end))
+	}
+	if wide {
+		// Stop before the digits overflow.
+		digits := 18
+		if width > digits {
+			digits = width
+		}
+		end = plus("digits", digits)
+	}
+
+	return block(foo,
+		assign(ident(variable), token.ASSIGN, intLit(0)),
+		forStmt(nil, and(notAtEnd(), binary(ident("offset"), token.LSS, end), inRange('0', byteAt(ident("offset")), '9')), incDec(ident("offset"), token.INC),
+			accumulate(variable, "int", byteAt(ident("offset")))),
+		ifElse(short, parseError("expected digit at index %d", ident("offset")), nil),
+		ifElse(ident("negative"), negate, nil))
+}
+
+// wideYear returns true when the year written by the directive at index i
+// may be read with more digits than its field width, because it is not
+// followed by a directive whose text may start with a digit.
+func (cg *CodeGenerator) wideYear(i int) bool {
+	if i+1 == len(cg.directives) {
+		return true
+	}
+	next := cg.directives[i+1]
+	if next.verb == 0 {
+		return next.literal[0] < '0' || next.literal[0] > '9'
+	}
+	if next.fieldFill() == '0' {
+		return false
+	}
+	return strings.ContainsRune("aAbBchnptzZ%+", next.verb)
+}
+
+// readPad returns the readDigits padding that matches the output of the
+// writeDigits padding flag pad.
+func readPad(pad byte) byte {
+	switch pad {
+	case '-':
+		return 0
+	case '_':
+		return ' '
+	default:
+		return '0'
+	}
+}
+
+// isSign returns the condition that the byte x is a plus or minus sign.
+func isSign(x func() ast.Expr) ast.Expr {
+	return or(binary(x(), token.EQL, charLit('+')), binary(x(), token.EQL, charLit('-')))
+}
+
+// notSign returns the condition that the byte at offset is neither a plus
+// nor a minus sign.
+func notSign() ast.Expr {
+	return paren(and(binary(byteAt(ident("offset")), token.NEQ, charLit('+')), binary(byteAt(ident("offset")), token.NEQ, charLit('-'))))
+}
+
+// readColonField returns the condition that b has a colon followed by two
+// digits after offset.
+func readColonField() ast.Expr {
+	return or(fewer(3), binary(byteAt(ident("offset")), token.NEQ, charLit(':')),
+		outOfRange(byteAt(plus("offset", 1)), '0', '9'), outOfRange(byteAt(plus("offset", 2)), '0', '9'))
+}
+
+// colonFieldValue returns the value of the two digits after the colon at
+// offset, multiplied by multiplier.
+func colonFieldValue() ast.Expr {
+	tens := binary(digitValue(byteAt(plus("offset", 1))), token.MUL, intLit(10))
+	return binary(paren(binary(tens, token.ADD, digitValue(byteAt(plus("offset", 2))))), token.MUL, ident("multiplier"))
+}
+
+// intArray returns the array literal of values.
+func intArray(values ...int) ast.Expr {
+	elts := make([]ast.Expr, len(values))
+	for i, v := range values {
+		elts[i] = intLit(v)
+	}
+	return compositeLit(arrayOf(&ast.Ellipsis{}, ident("int")), elts...)
+}
+
+// readOffset returns the code that reads a numeric zone offset into
+// zoneOffset. The offset has hours, and optionally minutes and seconds, as
+// selected by precision, and the fields are separated by colons when colon is
+// true.
+func (cg *CodeGenerator) readOffset(colon bool, precision int) code {
+	cg.parseZoneOffset = true
+
+	// Determine the indices of the digits of each field, relative to the
+	// sign.
+	var indices []int
+	var colons []int
+	index := 1
+	for field := 0; field < precision; field++ {
+		if field > 0 && colon {
+			colons = append(colons, index)
+			index++
+		}
+		indices = append(indices, index, index+1)
+		index += 2
+	}
+
+	foo := ifElse(or(fewer(index), notSign()), parseError("expected zone offset at index %d", ident("offset")), nil)
+	for _, i := range colons {
+		foo = join(foo, ifElse(binary(byteAt(plus("offset", i)), token.NEQ, charLit(':')),
+			parseError("expected ':' at index %d", plus("offset", i)), nil))
+	}
+
+	foo = join(foo, rangeStmt("_", "i", intArray(indices...),
+		ifInit(define("c", byteAt(binary(ident("offset"), token.ADD, ident("i")))), outOfRange(ident("c"), '0', '9'),
+			parseError("expected digit at index %d", binary(ident("offset"), token.ADD, ident("i"))), nil)))
+
+	multipliers := []int{36000, 3600, 600, 60, 10, 1}
+	var sum ast.Expr
+	for i, index := range indices {
+		term := binary(digitValue(byteAt(plus("offset", index))), token.MUL, intLit(multipliers[i]))
+		if sum == nil {
+			sum = term
+		} else {
+			sum = binary(sum, token.ADD, term)
+		}
+	}
+	return join(foo,
+		assign(ident("zoneOffset"), token.ASSIGN, sum),
+		ifElse(binary(byteAt(ident("offset")), token.EQL, charLit('-')), assign(ident("zoneOffset"), token.ASSIGN, unary(token.SUB, ident("zoneOffset"))), nil),
+		assign(ident("offset"), token.ADD_ASSIGN, intLit(index)))
+}
+
+// readZ returns the code that reads a zone offset written by writeZ.
+func (cg *CodeGenerator) readZ(colons int) code {
+	switch colons {
+	case 0:
+		return join(section("readZ"), cg.readOffset(false, 2))
+	case 1:
+		return join(section("readZ"), cg.readOffset(true, 2))
+	case 2:
+		return join(section("readZ"), cg.readOffset(true, 3))
+	}
+
+	// The hours are followed by minutes, and then by seconds, only when they
+	// are needed to write the offset exactly.
+	return join(section("readZ minimal"), block(define("sign", ident("offset")), cg.readOffset(false, 1),
+		rangeStmt("_", "multiplier", intArray(60, 1), join(
+			ifElse(readColonField(), breakStmt(), nil),
+			define("field", colonFieldValue()),
+			ifElse(binary(byteAt(ident("sign")), token.EQL, charLit('-')),
+				assign(ident("zoneOffset"), token.SUB_ASSIGN, ident("field")),
+				assign(ident("zoneOffset"), token.ADD_ASSIGN, ident("field"))),
+			assign(ident("offset"), token.ADD_ASSIGN, intLit(3))))))
+}
+
+// readZField returns the code that reads a zone offset written by
+// writeZField: a sign, preceded by padding spaces or followed by padding
+// zeros, and then hours that may have no leading zeros.
+func (cg *CodeGenerator) readZField(colons, width int, pad byte) code {
+	cg.parseZoneOffset = true
+
+	var foo code
+	if pad == '_' {
+		fieldWidth := width
+		if fieldWidth == 0 {
+			fieldWidth = [...]int{len("+hhmm"), len("+hh:mm"), len("+hh:mm:ss"), len("+hh:mm:ss")}[colons]
+		}
+		foo = cg.readFieldPad(fieldWidth-1, ' ')
+	}
+
+	// The first number holds the hours and minutes of %z, and the hours
+	// otherwise, and only has more digits when padded with zeros.
+	digits := 2
+	if colons == 0 {
+		digits = 4
+	}
+	if pad != '-' && pad != '_' && width-1 > digits {
+		digits = width - 1
+	}
+	foo = join(foo,
+		ifElse(or(atEnd(), notSign()), parseError("expected zone offset at index %d", ident("offset")), nil),
+		define("sign", ident("offset")),
+		incDec(ident("offset"), token.INC),
+		define("value", intLit(0)),
+		forStmt(define("start", ident("offset")), and(notAtEnd(), binary(binary(ident("offset"), token.SUB, ident("start")), token.LSS, intLit(digits)), inRange('0', byteAt(ident("offset")), '9')),
+			incDec(ident("offset"), token.INC),
+			accumulate("value", "int", byteAt(ident("offset")))),
+		ifElse(binary(ident("offset"), token.EQL, plus("sign", 1)), parseError("expected digit at index %d", ident("offset")), nil))
+
+	hours := assign(ident("zoneOffset"), token.ASSIGN, binary(ident("value"), token.MUL, intLit(3600)))
+	nextField := assign(ident("offset"), token.ADD_ASSIGN, intLit(3))
+	switch colons {
+	case 0:
+		foo = join(foo, assign(ident("zoneOffset"), token.ASSIGN, binary(
+			binary(binary(ident("value"), token.QUO, intLit(100)), token.MUL, intLit(3600)), token.ADD,
+			binary(binary(ident("value"), token.REM, intLit(100)), token.MUL, intLit(60)))))
+	case 1, 2:
+		values := []int{60}
+		if colons == 2 {
+			values = []int{60, 1}
+		}
+		foo = join(foo, hours, rangeStmt("_", "multiplier", intArray(values...), join(
+			ifElse(or(fewer(3), binary(byteAt(ident("offset")), token.NEQ, charLit(':'))),
+				parseError("expected ':' at index %d", ident("offset")), nil),
+			ifElse(or(outOfRange(byteAt(plus("offset", 1)), '0', '9'), outOfRange(byteAt(plus("offset", 2)), '0', '9')),
+				parseError("expected digit at index %d", plus("offset", 1)), nil),
+			assign(ident("zoneOffset"), token.ADD_ASSIGN, colonFieldValue()),
+			nextField)))
+	default:
+		// The hours are followed by minutes, and then by seconds, only
+		// when they are needed to write the offset exactly.
+		foo = join(foo, hours, rangeStmt("_", "multiplier", intArray(60, 1), join(
+			ifElse(readColonField(), breakStmt(), nil),
+			assign(ident("zoneOffset"), token.ADD_ASSIGN, colonFieldValue()),
+			nextField)))
+	}
+
+	return join(section("readZField"), block(foo,
+		ifElse(binary(byteAt(ident("sign")), token.EQL, charLit('-')), assign(ident("zoneOffset"), token.ASSIGN, unary(token.SUB, ident("zoneOffset"))), nil)))
+}
+
+// isZulu returns the condition that b has a 'Z' at offset.
+func isZulu() ast.Expr {
+	return and(notAtEnd(), binary(byteAt(ident("offset")), token.EQL, charLit('Z')))
+}
+
+func (cg *CodeGenerator) readTZ() code {
+	return join(section("readTZ"), ifElse(isZulu(), incDec(ident("offset"), token.INC), cg.readOffset(true, 2)))
+}
+
+// readLayoutOffset returns the code that reads a zone offset written by
+// writeLayoutOffset.
+func (cg *CodeGenerator) readLayoutOffset(zulu, colon bool, precision int) code {
+	if !zulu {
+		return join(section("readLayoutOffset"), cg.readOffset(colon, precision))
+	}
+	return join(section("readLayoutOffset"), ifElse(isZulu(), incDec(ident("offset"), token.INC), cg.readOffset(colon, precision)))
+}
+
+// readFraction returns the code that reads a fractional second written by
+// writeFraction.
+func (cg *CodeGenerator) readFraction(separator byte, width int, trim bool) code {
+	if !trim {
+		return join(section("readFraction"), cg.readStringConstant(string(separator)), cg.readNC('0', width))
+	}
+	return join(section("readFraction"), ifElse(and(binary(plus("offset", 1), token.LSS, lenOf("b")),
+		binary(byteAt(ident("offset")), token.EQL, charLit(separator)), inRange('0', byteAt(plus("offset", 1)), '9')), join(
+		incDec(ident("offset"), token.INC),
+		define("start", ident("offset")),
+		forStmt(nil, and(notAtEnd(), binary(binary(ident("offset"), token.SUB, ident("start")), token.LSS, intLit(width)), inRange('0', byteAt(ident("offset")), '9')),
+			incDec(ident("offset"), token.INC),
+			accumulate("nanosecond", "int", byteAt(ident("offset")))),
+		forStmt(define("i", binary(ident("offset"), token.SUB, ident("start"))), binary(ident("i"), token.LSS, intLit(9)), incDec(ident("i"), token.INC),
+			assign(ident("nanosecond"), token.MUL_ASSIGN, intLit(10)))), nil))
+}
+
+// readZoneName returns the code that reads the zone name that starts at
+// offset, and ends before the first byte for which isName is false.
+func readZoneName(isName ast.Expr) code {
+	return forStmt(define("start", ident("offset")), nil, incDec(ident("offset"), token.INC), join(
+		ifElse(notAtEnd(), ifInit(define("c", byteAt(ident("offset"))), isName, continueStmt(), nil), nil),
+		assign(ident("zoneName"), token.ASSIGN, sliceExpr(ident("b"), ident("start"), ident("offset"))),
+		breakStmt()))
+}
+
+func (cg *CodeGenerator) readZC() code {
+	cg.parseZoneName = true
+	c := func() ast.Expr { return ident("c") }
+	alphanumeric := []ast.Expr{
+		paren(inRange('A', c(), 'Z')),
+		paren(inRange('a', c(), 'z')),
+		paren(inRange('0', c(), '9')),
+	}
+	if cg.zoneStyle == ZoneLocation {
+		// Location names, such as "America/Port-au-Prince" and
+		// "Etc/GMT+5", also contain slashes, underscores, and signs.
+		cg.parseZoneLocation = true
+		var class []ast.Expr
+		for _, r := range "/_+-" {
+			class = append(class, binary(c(), token.EQL, charLit(byte(r))))
+		}
+		return join(section("readZC location"), readZoneName(or(append(alphanumeric, class...)...)))
+	}
+	sign := paren(and(paren(isSign(c)), binary(ident("offset"), token.EQL, ident("start"))))
+	return join(section("readZC"), readZoneName(or(append(alphanumeric, sign)...)))
+}
+
+func (cg *CodeGenerator) readPlus(upper, wide bool) code {
+	return join(
+		section("readPlus"),
+		cg.readWeekdayShort(upper),
+		cg.readStringConstant(" "),
+		cg.readMonthShort(upper),
+		cg.readStringConstant(" "),
+		cg.readE('_', 2),
+		cg.readStringConstant(" "),
+		cg.readTC(),
+		cg.readStringConstant(" "),
+		cg.readP(false),
+		cg.readStringConstant(" "),
+		cg.readZC(),
+		cg.readStringConstant(" "),
+		cg.readYC('0', 4, false, wide),
+	)
+}
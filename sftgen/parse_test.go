@@ -0,0 +1,242 @@
+package sftgen
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+// roundTripCases lists specs along with instants, as Go expressions, that the
+// generated parser must read back from the text written by the generated
+// function, such that formatting the parsed time writes the same text. A
+// field written more than once is parsed from the text written last, so those
+// specs write its most precise form last.
+var roundTripCases = []struct {
+	spec   string
+	config Config
+	times  []string
+}{
+	{
+		spec: "%Y-%m-%d %H:%M:%S",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)",
+			"time.Date(0, time.June, 15, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-44, time.March, 15, 12, 30, 0, 0, time.UTC)",
+			"time.Date(-12345, time.December, 31, 23, 59, 59, 0, time.UTC)",
+			"time.Date(123456, time.July, 4, 0, 0, 0, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%_10Y|%010Y|%-Y|%_Y|%3Y|%m|%d",
+		times: []string{
+			"time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-1, time.June, 15, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-150, time.June, 15, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-12345, time.June, 15, 0, 0, 0, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%C%y%m%d",
+		times: []string{
+			"time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-44, time.March, 15, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-150, time.June, 15, 0, 0, 0, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%_C|%-C|%4C %y-%j",
+		times: []string{
+			"time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-44, time.March, 15, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-12345, time.June, 15, 0, 0, 0, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%Y%m%d",
+		times: []string{
+			"time.Date(2006, time.January, 2, 0, 0, 0, 0, time.UTC)",
+			"time.Date(-44, time.March, 15, 0, 0, 0, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%c",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)",
+			"time.Date(-12345, time.June, 15, 0, 0, 0, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%a %A %b %B %d %e %j %u %w %y %H %I %k %l %M %S %p %P",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)",
+			"time.Date(1999, time.December, 31, 0, 0, 0, 0, time.UTC)",
+			"time.Date(2068, time.February, 29, 12, 59, 59, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%D %R %r|%T %x %X%n%t%%",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)",
+			"time.Date(1969, time.July, 20, 20, 17, 40, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%-d/%-m/%_H %^a %#b %-j %_y %-M %4S",
+		times: []string{
+			"time.Date(2006, time.January, 2, 3, 4, 5, 0, time.UTC)",
+			"time.Date(2020, time.October, 31, 23, 59, 59, 0, time.UTC)",
+		},
+	},
+	{
+		spec: "%F %T.%3N %z|%6N %:z|%N %::z|%12N|%_10N|%-12N",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.FixedZone(\"\", -7*3600))",
+			"time.Date(1883, time.November, 18, 12, 3, 58, 0, time.FixedZone(\"\", -4*3600-56*60-2))",
+		},
+	},
+	{
+		spec: "%F %T %-z|%_z|%-:z|%_::z|%-:::z|%_:::z|%10z|%_10z|%10:z|%_12::z|%8:z|%_5:::z|%10:::z",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone(\"\", -3*3600-30*60))",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone(\"\", 10*3600+30*60))",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone(\"\", -30))",
+			"time.Date(1883, time.November, 18, 12, 3, 58, 0, time.FixedZone(\"\", -4*3600-56*60-2))",
+		},
+	},
+	{
+		spec: "%F %T.%3 %1|%4 %:::z",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.FixedZone(\"\", 5*3600+30*60))",
+			"time.Date(1883, time.November, 18, 12, 3, 58, 0, time.FixedZone(\"\", -4*3600-56*60-2))",
+		},
+	},
+	{
+		spec: "%F %2 %Z",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)",
+			"time.Date(2006, time.July, 4, 0, 30, 0, 0, time.FixedZone(\"EST\", -5*3600))",
+		},
+	},
+	{
+		spec: "%+",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone(\"ACWST\", 8*3600+45*60))",
+		},
+	},
+	{
+		spec: "%s.%N|%s %z",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)",
+			"time.Date(1900, time.January, 2, 15, 4, 5, 1, time.FixedZone(\"\", 3600))",
+		},
+	},
+	{
+		spec:   "2006-01-02T15:04:05.999999999Z07:00",
+		config: Config{Layout: true},
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 120000000, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.FixedZone(\"\", -7*3600))",
+		},
+	},
+	{
+		spec:   "Mon Jan _2 15:04:05.000000 MST 2006",
+		config: Config{Layout: true},
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 123456000, time.UTC)",
+		},
+	},
+	{
+		spec: "%_3N|%-3N %_12N|%-12N",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 500000000, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 102030400, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 10, time.UTC)",
+		},
+	},
+	{
+		spec: "%-N %_N",
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 120000000, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)",
+		},
+	},
+	{
+		spec:   "15:04:05.999999999999 MST",
+		config: Config{Layout: true},
+		times: []string{
+			"time.Date(2006, time.January, 2, 15, 4, 5, 120000000, time.UTC)",
+			"time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)",
+		},
+	},
+}
+
+// TestParseRoundTrip generates a function and a parser for every spec, then
+// runs a program that formats every instant, parses the text, and formats the
+// parsed time again.
+func TestParseRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping round trip suite in short mode")
+	}
+	files := make(map[string][]byte)
+	main := new(bytes.Buffer)
+	main.WriteString(`package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func check(format func([]byte, time.Time) []byte, parse func([]byte) (time.Time, error), t time.Time) string {
+	text := format(nil, t)
+	parsed, err := parse(text)
+	if err != nil {
+		return err.Error()
+	}
+	if got := format(nil, parsed); string(got) != string(text) {
+		return fmt.Sprintf("GOT: %q; WANT: %q", got, text)
+	}
+	return "ok"
+}
+
+func main() {
+`)
+
+	for i, c := range roundTripCases {
+		config := c.config
+		config.FuncName = fmt.Sprintf("format%d", i)
+		config.ParseFuncName = fmt.Sprintf("parse%d", i)
+		config.AllowExtra = true
+		cg, err := NewCodeGenerator(c.spec, &config)
+		if err != nil {
+			t.Fatalf("%q: %s", c.spec, err)
+		}
+		files[config.FuncName+".go"] = cg.Bytes()
+
+		for _, when := range c.times {
+			fmt.Fprintf(main, "\tfmt.Println(check(%s, %s, %s))\n", config.FuncName, config.ParseFuncName, when)
+		}
+	}
+	main.WriteString("}\n")
+	files["main.go"] = main.Bytes()
+	output := runProgram(t, files)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for _, c := range roundTripCases {
+		for _, when := range c.times {
+			if !scanner.Scan() {
+				t.Fatalf("%q %s: missing output", c.spec, when)
+			}
+			if got := scanner.Text(); got != "ok" {
+				t.Errorf("%q %s: %s", c.spec, when, got)
+			}
+		}
+	}
+	if scanner.Scan() {
+		t.Errorf("GOT: %q; WANT: no more output", scanner.Text())
+	}
+}
@@ -0,0 +1,178 @@
+// Package strftime formats time.Time values according to a strftime(3) style
+// time format spec that is only known at runtime.
+//
+// It accepts the same specs, with the same semantics, as the code emitted by
+// the sftgen package, including the GNU flags and field widths, and the extra
+// verbs %1 through %4. A spec is compiled once into a list of operations,
+// which are then interpreted for every formatted time. Composite verbs such as
+// %F and %T are compiled into the operations of the verbs they are made of,
+// and the fields of each time are computed once, however many verbs write
+// them, so that formatting does not allocate. It is still slower than a
+// generated function, but serves as a fallback when no generated function
+// exists for a spec.
+//
+// Each operation is formatted by the reference interpreter that the fuzz
+// tests emitted by sftgen compare generated functions against, so that the
+// semantics of the verbs are only implemented once outside of the generator.
+package strftime
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/karrick/sft/internal/reference"
+)
+
+// verbs lists the formatting verbs that a spec may use.
+const verbs = "aAbBcCdDeFgGhHIjklmMnNpPrRsStTuUVwWxXyYzZ%+1234"
+
+// op is a single operation of a compiled spec: either literal text, a
+// formatting verb along with its flags, or a composite verb whose operations
+// are padded to its field width as a whole.
+type op struct {
+	literal string // literal text, used when verb is 0
+	ops     []op   // operations of a composite verb, padded by pad and width
+
+	verb     byte
+	pad      byte // '-' for no padding, '_' for spaces, '0' for zeros, or 0 for the verb's default
+	upcase   bool // '^' flag: use upper case
+	swapcase bool // '#' flag: use the opposite case
+	width    int  // field width, or 0 for the verb's default width
+	colons   int  // colons between the field width and %z, selecting the form of the offset
+}
+
+// Formatter formats time.Time values according to a compiled spec. It is
+// safe for concurrent use by multiple goroutines.
+type Formatter struct {
+	spec string
+	ops  []op
+}
+
+// Compile returns a Formatter for spec, or an error when spec is not a valid
+// time format spec.
+func Compile(spec string) (*Formatter, error) {
+	ops, err := compile(spec)
+	if err != nil {
+		return nil, err
+	}
+	return &Formatter{spec: spec, ops: ops}, nil
+}
+
+// compile returns the operations of spec. A composite verb without a field
+// width is replaced by the operations of the verbs it is made of, and
+// adjacent literal text is merged into a single operation.
+func compile(spec string) ([]op, error) {
+	var ops []op
+	var err error
+
+	literal := func(s string) {
+		if n := len(ops); n > 0 && ops[n-1].verb == 0 {
+			ops[n-1].literal += s
+			return
+		}
+		ops = append(ops, op{literal: s})
+	}
+
+	// The interpreter appends the literal text between the verbs to its
+	// buffer, which is emptied at every verb.
+	rest := reference.Interpret(nil, spec, func(buf []byte, verb, pad byte, upcase, swapcase bool, width, colons, index int) []byte {
+		if err != nil {
+			return buf[:0]
+		}
+		if len(buf) > 0 {
+			literal(string(buf))
+		}
+		if err = checkVerb(verb, colons, index); err != nil {
+			return buf[:0]
+		}
+		sub := reference.Composite(verb, upcase)
+		if sub == "" {
+			ops = append(ops, op{verb: verb, pad: pad, upcase: upcase, swapcase: swapcase, width: width, colons: colons})
+			return buf[:0]
+		}
+		var subOps []op
+		if subOps, err = compile(sub); err != nil {
+			return buf[:0]
+		}
+		if width > 0 && pad != '-' {
+			ops = append(ops, op{verb: verb, pad: pad, width: width, ops: subOps})
+			return buf[:0]
+		}
+		for _, o := range subOps {
+			if o.verb == 0 {
+				literal(o.literal)
+				continue
+			}
+			ops = append(ops, o)
+		}
+		return buf[:0]
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		literal(string(rest))
+	}
+	return ops, nil
+}
+
+// checkVerb returns an error when a formatting verb, found at index within
+// the spec, cannot be formatted with its colons.
+func checkVerb(verb byte, colons, index int) error {
+	switch {
+	case verb == 0:
+		return errors.New("cannot find closing format verb")
+	case colons > 0 && verb != 'z':
+		return fmt.Errorf("cannot use colons with format verb %q at index %d", verb, index)
+	case colons > 3:
+		return fmt.Errorf("cannot use %d colons with format verb %q at index %d", colons, verb, index)
+	case strings.IndexByte(verbs, verb) < 0:
+		return fmt.Errorf("cannot recognize format verb %q at index %d", verb, index)
+	}
+	return nil
+}
+
+// MustCompile is like Compile but panics when spec is not a valid time format
+// spec.
+func MustCompile(spec string) *Formatter {
+	f, err := Compile(spec)
+	if err != nil {
+		panic("strftime: " + err.Error())
+	}
+	return f
+}
+
+// Spec returns the spec the Formatter was compiled from.
+func (f *Formatter) Spec() string { return f.spec }
+
+// Format returns t formatted according to the spec of the Formatter.
+func (f *Formatter) Format(t time.Time) string {
+	return string(f.AppendFormat(make([]byte, 0, 64), t))
+}
+
+// AppendFormat appends t formatted according to the spec of the Formatter to
+// buf, and returns the extended buffer.
+func (f *Formatter) AppendFormat(buf []byte, t time.Time) []byte {
+	fields := reference.FieldsOf(t)
+	return appendOps(buf, f.ops, &fields)
+}
+
+// appendOps appends the time whose fields are fields formatted according to
+// ops to buf.
+func appendOps(buf []byte, ops []op, fields *reference.Fields) []byte {
+	for i := range ops {
+		o := &ops[i]
+		switch {
+		case o.verb == 0:
+			buf = append(buf, o.literal...)
+		case o.ops != nil:
+			start := len(buf)
+			buf = reference.Pad(appendOps(buf, o.ops, fields), start, o.pad, o.width)
+		default:
+			buf = reference.AppendVerb(buf, o.verb, o.pad, o.upcase, o.swapcase, o.width, o.colons, fields)
+		}
+	}
+	return buf
+}
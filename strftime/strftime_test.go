@@ -0,0 +1,260 @@
+package strftime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/karrick/sft/internal/reference"
+)
+
+func TestFormatterAppendFormat(t *testing.T) {
+	first := time.Unix(1136171045, 123456789).UTC()
+	second := time.Unix(946638000, 1000).UTC()
+
+	// Expected values were produced by GNU date.
+	cases := []struct {
+		spec string
+		when time.Time
+		want string
+	}{
+		{
+			spec: "%a %A %b %B %C %d %D %e %F %g %G %h %H %I %j %k %l %m %M %n %N %p %P %r %R %s %S %t %T %u %U %V %w %W %x %X %y %Y %z %Z %%",
+			when: first,
+			want: "Mon Monday Jan January 20 02 01/02/06  2 2006-01-02 06 2006 Jan 03 03 002  3  3 01 04 \n 123456789 AM am 03:04:05 AM 03:04 1136171045 05 \t 03:04:05 1 01 01 1 01 01/02/06 03:04:05 06 2006 +0000 UTC %",
+		},
+		{
+			spec: "%a %A %b %B %C %d %D %e %F %g %G %h %H %I %j %k %l %m %M %n %N %p %P %r %R %s %S %t %T %u %U %V %w %W %x %X %y %Y %z %Z %%",
+			when: second,
+			want: "Fri Friday Dec December 19 31 12/31/99 31 1999-12-31 99 1999 Dec 11 11 365 11 11 12 00 \n 000001000 AM am 11:00:00 AM 11:00 946638000 00 \t 11:00:00 5 52 52 5 52 12/31/99 11:00:00 99 1999 +0000 UTC %",
+		},
+		{
+			spec: "%-d %_H %010d %^a %#b %#p %#Z %3N %6N %10a %5j %_5j %-j",
+			when: first,
+			want: "2  3 0000000002 MON JAN am utc 123 123456        Mon 00002     2 2",
+		},
+		{
+			spec: "%-d %_H %010d %^a %#b %#p %#Z %3N %6N %10a %5j %_5j %-j",
+			when: second,
+			want: "31 11 0000000031 FRI DEC am utc 000 000001        Fri 00365   365 365",
+		},
+		{
+			spec: "%Y-%m-%dT%T.%3%1 %2",
+			when: first.In(time.FixedZone("XYZ", -7*3600)),
+			want: "2006-01-01T20:04:05.123-07:00 8",
+		},
+		{
+			spec: "%z %:z %::z %:::z",
+			when: time.Date(1800, time.January, 1, 0, 0, 0, 0, time.FixedZone("LMT", -(4*3600+56*60+2))),
+			want: "-0456 -04:56 -04:56:02 -04:56:02",
+		},
+		{
+			spec: "%z %:z %::z %:::z",
+			when: first.In(time.FixedZone("+1030", 10*3600+30*60)),
+			want: "+1030 +10:30 +10:30:00 +10:30",
+		},
+		{
+			spec: "%z %:z %::z %:::z",
+			when: first,
+			want: "+0000 +00:00 +00:00:00 +00",
+		},
+		{
+			spec: "%-z|%_z|%-:z|%_::z|%-:::z|%_:::z|%10z|%_10z",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 0, time.FixedZone("", -(5*3600+30*60))),
+			want: "-530| -530|-5:30| -5:30:00|-5:30| -5:30|-000000530|      -530",
+		},
+		{
+			spec: "%-z|%_z|%-:::z|%_:::z|%10:::z",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 0, time.FixedZone("", 10*3600)),
+			want: "+1000|+1000|+10|+10|+000000010",
+		},
+		{
+			spec: "%8:z|%_5:z|%9::z|%_8:::z|%8:::z|%1:z",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 0, time.FixedZone("", -(3*3600+30*60))),
+			want: "-0003:30|-3:30|-03:30:00|   -3:30|-0003:30|-3:30",
+		},
+		{
+			spec: "%10N|%_12N|%-12N|%012N",
+			when: time.Date(2006, time.January, 2, 3, 4, 5, 11, time.UTC),
+			want: "0000000110|000000011   |000000011|000000011000",
+		},
+		{
+			spec: "%1 %2 %4",
+			when: time.Date(2020, time.March, 4, 0, 5, 6, 7000, time.UTC),
+			want: "Z 12 000007",
+		},
+	}
+
+	for _, c := range cases {
+		f, err := Compile(c.spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := string(f.AppendFormat([]byte("prefix "), c.when)), "prefix "+c.want; got != want {
+			t.Errorf("%q: GOT: %q; WANT: %q", c.spec, got, want)
+		}
+		if got, want := f.Format(c.when), c.want; got != want {
+			t.Errorf("%q: GOT: %q; WANT: %q", c.spec, got, want)
+		}
+	}
+}
+
+func TestFormatterYears(t *testing.T) {
+	// Like GNU, the sign of a negative year counts toward an explicit field
+	// width, with padding spaces before it and padding zeros after it.
+	// Without a field width, %Y and %G write years like time.Format.
+	f := MustCompile("%Y|%_10Y|%010Y|%-Y|%_Y|%3Y|%C|%_C|%-C|%4C|%G|%6G|%_6G")
+	cases := []struct {
+		year int
+		want string
+	}{
+		{44, "0044|        44|0000000044|44|  44|044|00| 0|0|0000|0044|000044|    44"},
+		{-1, "-0001|        -1|-000000001|-1|   -1|-01|-0|-0|-0|-000|-0001|-00001|    -1"},
+		{-150, "-0150|      -150|-000000150|-150| -150|-150|-1|-1|-1|-001|-0150|-00150|  -150"},
+		{-12345, "-12345|    -12345|-000012345|-12345|-12345|-12345|-123|-123|-123|-123|-12345|-12345|-12345"},
+	}
+
+	for _, c := range cases {
+		when := time.Date(c.year, time.June, 15, 0, 0, 0, 0, time.UTC)
+		if got, want := f.Format(when), c.want; got != want {
+			t.Errorf("%d: GOT: %q; WANT: %q", c.year, got, want)
+		}
+	}
+}
+
+func TestFormatterComposites(t *testing.T) {
+	when := time.Date(2006, time.January, 2, 0, 4, 5, 0, time.FixedZone("MST", -7*3600))
+
+	// Expected values were produced by GNU date, except for %+, which it
+	// does not support.
+	cases := []struct {
+		spec string
+		want string
+	}{
+		{"%c", "Mon Jan  2 00:04:05 2006"},
+		{"%^c", "MON JAN  2 00:04:05 2006"},
+		{"%+", "Mon Jan  2 00:04:05 AM MST 2006"},
+		{"%r", "12:04:05 AM"},
+		{"%12R|%-12R|%012T", "       00:04|00:04|000000:04:05"},
+		{"%5s %5u %_5w", "1136185445 00001     1"},
+		{"%I %l %-I", "12 12 12"},
+	}
+
+	for _, c := range cases {
+		if got, want := MustCompile(c.spec).Format(when), c.want; got != want {
+			t.Errorf("%q: GOT: %q; WANT: %q", c.spec, got, want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := []struct {
+		spec string
+		want string
+	}{
+		{"%F %", "cannot find closing format verb"},
+		{"%F %Q", "cannot recognize format verb 'Q' at index 4"},
+		{"%:Z", "cannot use colons with format verb 'Z' at index 2"},
+		{"%::::z", "cannot use 4 colons with format verb 'z' at index 5"},
+	}
+
+	for _, c := range cases {
+		_, err := Compile(c.spec)
+		if got, want := err, c.want; got == nil || got.Error() != want {
+			t.Errorf("%q: GOT: %v; WANT: %q", c.spec, got, want)
+		}
+	}
+}
+
+// TestFormatterMatchesReference cross-checks compiled specs with the
+// reference interpreter, which interprets the spec for every formatted time.
+func TestFormatterMatchesReference(t *testing.T) {
+	specs := []string{
+		"%a %A %b %B %C %d %D %e %F %g %G %h %H %I %j %k %l %m %M %n %N %p %P %r %R %s %S %t %T %u %U %V %w %W %x %X %y %Y %z %Z %%",
+		"%c|%^c|%#c|%+|%^+|%10c|%-30+",
+		"%^030c|%_12F|%-12T|%12D %r %R %x %X|%F%T",
+		"%-d %_H %010d %^a %#b %#p %#Z %3N %6N %10a %5j %_5j %-j %_^10B %#10P",
+		"%Y|%_10Y|%010Y|%-Y|%_Y|%3Y|%C|%_C|%-C|%4C|%G|%6G|%_6G|%13s|%_14s|%-s",
+		"%z|%:z|%::z|%:::z|%10z|%_10:z|%-::z|%010:::z|%8:z|%_5:::z|%1:z",
+		"%1%2%3%4|%3%1|%Y%m%dT%H%M%S|100%%|%_5n|%3t",
+		"%U %_5U %-V %03W %5u %_5w %-4g",
+		"literal only",
+		"",
+	}
+	whens := []time.Time{
+		time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC),
+		time.Date(1999, time.December, 31, 0, 0, 0, 1000, time.FixedZone("XYZ", -7*3600)),
+		time.Date(1800, time.January, 1, 12, 0, 0, 0, time.FixedZone("LMT", -(4*3600+56*60+2))),
+		time.Date(-44, time.March, 15, 12, 30, 0, 0, time.FixedZone("", 5*3600+30*60)),
+		time.Date(-12345, time.June, 15, 0, 0, 0, 0, time.FixedZone("America/Argentina/Buenos_Aires", -3*3600)),
+		time.Date(123456, time.July, 4, 23, 59, 59, 999999999, time.UTC),
+	}
+
+	for _, spec := range specs {
+		f := MustCompile(spec)
+		for _, when := range whens {
+			if got, want := f.Format(when), string(reference.Format(nil, spec, when)); got != want {
+				t.Errorf("%q %s: GOT: %q; WANT: %q", spec, when, got, want)
+			}
+		}
+	}
+}
+
+func TestCompileComposites(t *testing.T) {
+	// Composite verbs without a field width are compiled into the verbs they
+	// are made of, with the adjacent literal text merged.
+	f := MustCompile("[%F %T]")
+	var got string
+	for _, o := range f.ops {
+		if o.verb == 0 {
+			got += o.literal
+			continue
+		}
+		got += "%" + string(o.verb)
+	}
+	if want := "[%Y-%m-%d %H:%M:%S]"; got != want {
+		t.Errorf("GOT: %q; WANT: %q", got, want)
+	}
+
+	// A padded composite verb keeps its verbs together.
+	f = MustCompile("%12R")
+	if got, want := len(f.ops), 1; got != want {
+		t.Fatalf("GOT: %v; WANT: %v", got, want)
+	}
+	if got, want := len(f.ops[0].ops), 3; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func TestFormatterAllocs(t *testing.T) {
+	f := MustCompile("%c %+ %F %T.%N %z %:::z %Z %^a %#Z %#p %P %30c %2")
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.FixedZone("MST", -7*3600))
+	buf := make([]byte, 0, 256)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf = f.AppendFormat(buf[:0], when)
+	})
+	if got, want := allocs, 0.0; got != want {
+		t.Errorf("GOT: %v; WANT: %v", got, want)
+	}
+}
+
+func BenchmarkFormatterAppendFormat(b *testing.B) {
+	f := MustCompile("%F %T.%N %z")
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = f.AppendFormat(buf[:0], when)
+	}
+}
+
+func BenchmarkStdlibAppendFormat(b *testing.B) {
+	const layout = "2006-01-02 15:04:05.000000000 -0700"
+	when := time.Date(2006, time.January, 2, 15, 4, 5, 123456789, time.UTC)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = when.AppendFormat(buf[:0], layout)
+	}
+}